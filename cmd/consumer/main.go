@@ -10,36 +10,118 @@ import (
 	"time"
 
 	"kafka-consumer/internal/api"
+	"kafka-consumer/internal/app"
 	"kafka-consumer/internal/config"
 	"kafka-consumer/internal/kafka"
+	"kafka-consumer/internal/pkg/customerrors"
+	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/pkg/tracing"
 	"kafka-consumer/internal/processor"
 )
 
 func main() {
+	cfg := config.LoadConfig()
+
+	// Architecture selects which implementation runs: "new" is
+	// internal/app.Application (internal/delivery/queue.Handler, the real
+	// image processor, schema registry/compression support, etc.); anything
+	// else falls back to the original consumer below.
+	if cfg.Architecture == "new" {
+		runNewArchitecture(cfg)
+		return
+	}
+	runLegacy(cfg)
+}
+
+// runNewArchitecture runs internal/app.Application, the consolidated
+// rewrite of the consumer built up across the delivery/queue, delivery/api
+// and processor packages.
+func runNewArchitecture(cfg *config.Config) {
+	application := app.New(cfg)
+	if err := application.Init(); err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := application.Run(context.Background()); err != nil {
+		log.Fatalf("Application exited with error: %v", err)
+	}
+}
+
+// runLegacy runs the original internal/kafka.Consumer + internal/api stack.
+func runLegacy(cfg *config.Config) {
 	log.Println("=== Kafka Consumer для обработки AI-команд ===")
 
-	// Load configuration
-	cfg := config.LoadConfig()
 	log.Printf("Конфигурация: brokers=%v, topic=%s, groupID=%s",
 		cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID)
 
+	// Initialize distributed tracing
+	shutdownTracing, err := tracing.Init("kafka-consumer", os.Stdout, tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		Endpoint:     cfg.TracingEndpoint,
+		Headers:      cfg.TracingHeaders,
+		SamplerRatio: cfg.TracingSamplerRatio,
+		Insecure:     cfg.TracingInsecure,
+	})
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать трейсинг: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Create API server for health checks
 	apiServer := api.NewServer()
 
 	// Create processor (stub for now)
 	proc := processor.NewStubProcessor()
 
+	// Create the DLQ producer used for messages that hit a terminal error
+	// or exhaust their retries
+	dlqProducer := kafka.NewDLQProducer(cfg.KafkaBrokers, cfg.KafkaDLQTopic)
+	defer dlqProducer.Close()
+
+	retryCfg := kafka.RetryConfig{
+		MaxAttempts: cfg.MaxRetries,
+		BaseBackoff: cfg.RetryBaseBackoff,
+	}
+
+	security := kafka.SecurityConfig{
+		SASLMechanism:      cfg.KafkaSecurity.SASLMechanism,
+		Username:           cfg.KafkaSecurity.Username,
+		Password:           cfg.KafkaSecurity.Password,
+		TLSEnabled:         cfg.KafkaSecurity.TLSEnabled,
+		CAFile:             cfg.KafkaSecurity.CAFile,
+		CertFile:           cfg.KafkaSecurity.CertFile,
+		KeyFile:            cfg.KafkaSecurity.KeyFile,
+		InsecureSkipVerify: cfg.KafkaSecurity.InsecureSkipVerify,
+		OAuth2: kafka.OAuth2Config{
+			TokenURL:     cfg.KafkaSecurity.OAuth2.TokenURL,
+			ClientID:     cfg.KafkaSecurity.OAuth2.ClientID,
+			ClientSecret: cfg.KafkaSecurity.OAuth2.ClientSecret,
+			Scopes:       cfg.KafkaSecurity.OAuth2.Scopes,
+		},
+	}
+
 	// Create Kafka consumer
-	consumer := kafka.NewConsumer(
+	consumer, err := kafka.NewConsumer(
 		cfg.KafkaBrokers,
 		cfg.KafkaTopic,
 		cfg.KafkaGroupID,
 		proc,
+		dlqProducer,
+		retryCfg,
+		security,
 	)
+	if err != nil {
+		appErr := customerrors.ErrServiceUnavailable.WithDetails("failed to set up Kafka consumer authentication: " + err.Error())
+		log.Fatalf("Ошибка настройки consumer: %v", appErr)
+	}
 	consumer.SetOnMessageProcessed(apiServer.IncrementMessagesCount)
+	consumer.SetOnDLQ(apiServer.IncrementDLQCount)
 
 	// Create Kafka producer for sending test messages
-	producer := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopic)
+	producer, err := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopic, security)
+	if err != nil {
+		appErr := customerrors.ErrServiceUnavailable.WithDetails("failed to set up Kafka producer authentication: " + err.Error())
+		log.Fatalf("Ошибка настройки producer: %v", appErr)
+	}
 	apiServer.SetProducer(producer)
 	defer producer.Close()
 
@@ -68,6 +150,7 @@ func main() {
 	mux.HandleFunc("/ready", apiServer.ReadyHandler)
 	mux.HandleFunc("/stats", apiServer.StatsHandler)
 	mux.HandleFunc("/send", apiServer.SendHandler)
+	mux.Handle("/metrics", metrics.Handler())
 
 	httpServer := &http.Server{
 		Addr:    ":" + httpPort,
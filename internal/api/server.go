@@ -7,18 +7,20 @@ import (
 	"sync/atomic"
 	"time"
 
-	"kafka-consumer/internal/models"
+	"kafka-consumer/internal/pkg/tracing"
+	pb "kafka-consumer/proto"
 )
 
 // MessageSender interface for sending messages to Kafka
 type MessageSender interface {
-	SendMessage(ctx context.Context, cmd models.ImageCommand) error
+	SendMessage(ctx context.Context, cmd *pb.ImageCommand) error
 }
 
 // Server represents the HTTP API server for health checks and message sending
 type Server struct {
 	startTime      time.Time
 	messagesCount  int64
+	dlqCount       int64
 	kafkaConnected bool
 	producer       MessageSender
 }
@@ -74,6 +76,16 @@ func (s *Server) GetMessagesCount() int64 {
 	return atomic.LoadInt64(&s.messagesCount)
 }
 
+// IncrementDLQCount increments the dead-letter counter
+func (s *Server) IncrementDLQCount() {
+	atomic.AddInt64(&s.dlqCount, 1)
+}
+
+// GetDLQCount returns the current dead-letter count
+func (s *Server) GetDLQCount() int64 {
+	return atomic.LoadInt64(&s.dlqCount)
+}
+
 // HealthHandler handles health check requests
 func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -127,6 +139,7 @@ func (s *Server) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	stats := map[string]interface{}{
 		"uptime_seconds":     time.Since(s.startTime).Seconds(),
 		"messages_processed": s.GetMessagesCount(),
+		"dlq_count":          s.GetDLQCount(),
 		"kafka_connected":    s.kafkaConnected,
 	}
 
@@ -170,17 +183,20 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cmd := models.ImageCommand{
-		ID:         req.ID,
-		Command:    models.CommandType(req.Command),
-		ImageURL:   req.ImageURL,
-		Parameters: req.Parameters,
+	cmd := &pb.ImageCommand{
+		Id:       req.ID,
+		Command:  commandTypeFromString(req.Command),
+		ImageUrl: req.ImageURL,
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, span := tracing.StartSpan(r.Context(), "SendHandler")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	if err := s.producer.SendMessage(ctx, cmd); err != nil {
+		tracing.RecordError(ctx, err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(SendMessageResponse{
 			Success: false,
@@ -197,3 +213,24 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 		ID:      req.ID,
 	})
 }
+
+// commandTypeFromString maps the request's command string onto the protobuf
+// enum, defaulting to unspecified for anything unrecognized.
+func commandTypeFromString(command string) pb.CommandType {
+	switch command {
+	case "resize":
+		return pb.CommandType_COMMAND_TYPE_RESIZE
+	case "filter":
+		return pb.CommandType_COMMAND_TYPE_FILTER
+	case "transform":
+		return pb.CommandType_COMMAND_TYPE_TRANSFORM
+	case "analyze":
+		return pb.CommandType_COMMAND_TYPE_ANALYZE
+	case "crop":
+		return pb.CommandType_COMMAND_TYPE_CROP
+	case "remove_background":
+		return pb.CommandType_COMMAND_TYPE_REMOVE_BACKGROUND
+	default:
+		return pb.CommandType_COMMAND_TYPE_UNSPECIFIED
+	}
+}
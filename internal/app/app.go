@@ -9,22 +9,34 @@ import (
 	"syscall"
 	"time"
 
+	kafkago "github.com/segmentio/kafka-go"
+	"golang.org/x/oauth2"
+
 	"kafka-consumer/internal/config"
 	"kafka-consumer/internal/delivery/api"
 	"kafka-consumer/internal/delivery/queue"
+	"kafka-consumer/internal/jobs"
 	"kafka-consumer/internal/kafka"
+	"kafka-consumer/internal/pkg/auth"
+	"kafka-consumer/internal/pkg/customerrors"
 	"kafka-consumer/internal/pkg/logger"
 	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/pkg/schemaregistry"
+	"kafka-consumer/internal/pkg/tracing"
 	"kafka-consumer/internal/processor"
+	"kafka-consumer/internal/puller"
 )
 
 // Application represents the main application
 type Application struct {
-	cfg        *config.Config
-	apiServer  *api.Server
-	handler    *queue.Handler
-	producer   *kafka.Producer
-	httpServer *http.Server
+	cfg             *config.Config
+	apiServer       *api.Server
+	handler         *queue.Handler
+	producer        *kafka.Producer
+	dlqProducer     *kafka.DLQProducer
+	httpServer      *http.Server
+	tokenSource     oauth2.TokenSource
+	shutdownTracing func(context.Context) error
 }
 
 // New creates a new Application instance
@@ -48,15 +60,46 @@ func (a *Application) Init() error {
 		"message_format", a.cfg.MessageFormat,
 	)
 
+	// Initialize distributed tracing
+	shutdownTracing, err := tracing.Init("kafka-consumer", os.Stdout, tracing.Config{
+		Exporter:     a.cfg.TracingExporter,
+		Endpoint:     a.cfg.TracingEndpoint,
+		Headers:      a.cfg.TracingHeaders,
+		SamplerRatio: a.cfg.TracingSamplerRatio,
+		Insecure:     a.cfg.TracingInsecure,
+	})
+	if err != nil {
+		logger.Error("Failed to initialize tracing", "error", err)
+		return err
+	}
+	a.shutdownTracing = shutdownTracing
+
 	// Create API server with chi router
 	a.apiServer = api.NewServer()
 
+	// Build the shared OAuth2 client-credentials token source, if configured
+	if a.cfg.OAuthTokenURL != "" {
+		a.tokenSource = auth.NewTokenSource(context.Background(), auth.Config{
+			TokenURL:     a.cfg.OAuthTokenURL,
+			ClientID:     a.cfg.OAuthClientID,
+			ClientSecret: a.cfg.OAuthClientSecret,
+			Scopes:       a.cfg.OAuthScopes,
+			Audience:     a.cfg.OAuthAudience,
+		})
+	}
+
 	// Create processor based on configuration
 	var proc processor.Processor
-	var err error
 	if a.cfg.ProcessorMode == "real" {
 		logger.Info("Using real image processor", "output_dir", a.cfg.OutputDir)
-		proc, err = processor.NewRealProcessor(a.cfg)
+
+		imgPuller, err := a.newImagePuller(context.Background())
+		if err != nil {
+			logger.Error("Failed to create image puller", "error", err)
+			return err
+		}
+
+		proc, err = processor.NewRealProcessor(a.cfg.OutputDir, imgPuller, a.cfg.PipelineMaxSteps, nil)
 		if err != nil {
 			logger.Error("Failed to create processor", "error", err)
 			return err
@@ -67,11 +110,44 @@ func (a *Application) Init() error {
 	}
 
 	// Create message decoder
-	format := queue.FormatJSON
-	if a.cfg.MessageFormat == "protobuf" {
-		format = queue.FormatProtobuf
+	var decoder *queue.Decoder
+	if a.cfg.MessageFormat == "schema_registry" {
+		registry := schemaregistry.NewClient(schemaregistry.Config{
+			URL:             a.cfg.SchemaRegistryURL,
+			Username:        a.cfg.SchemaRegistryUsername,
+			Password:        a.cfg.SchemaRegistryPassword,
+			SubjectStrategy: a.cfg.SchemaRegistrySubjectStrategy,
+			CacheTTL:        a.cfg.SchemaRegistryCacheTTL,
+		})
+		decoder = queue.NewSchemaRegistryDecoder(registry)
+	} else {
+		format := queue.FormatJSON
+		if a.cfg.MessageFormat == "protobuf" {
+			format = queue.FormatProtobuf
+		}
+		decoder = queue.NewDecoder(format)
+	}
+
+	// Create the DLQ producer used for undecodable/unrecoverable messages
+	dlqProducer := kafka.NewDLQProducer(a.cfg.KafkaBrokers, a.cfg.DLQTopic)
+	a.dlqProducer = dlqProducer
+
+	retryCfg := queue.RetryConfig{
+		MaxAttempts:    a.cfg.RetryMaxAttempts,
+		InitialBackoff: a.cfg.RetryInitialBackoff,
+		MaxBackoff:     a.cfg.RetryMaxBackoff,
 	}
-	decoder := queue.NewDecoder(format)
+
+	dialer := a.kafkaDialer()
+
+	// Track job lifecycle for async /send requests and notify their callers
+	// via webhook when a job reaches a terminal state.
+	jobStore := jobs.NewMemoryStore()
+	var notifier jobs.Notifier
+	if a.cfg.WebhookSecret != "" {
+		notifier = jobs.NewWebhookNotifier(a.cfg.WebhookSecret)
+	}
+	jobTracker := jobs.NewTracker(jobStore, notifier)
 
 	// Create Kafka queue handler (consumer)
 	a.handler = queue.NewHandler(
@@ -80,14 +156,46 @@ func (a *Application) Init() error {
 		a.cfg.KafkaGroupID,
 		proc,
 		decoder,
+		retryCfg,
+		dlqProducer,
+		dialer,
+		jobTracker,
+		queue.GzipSnappyCodec{},
 	)
 
 	// Create Kafka producer for sending test messages
-	a.producer = kafka.NewProducer(a.cfg.KafkaBrokers, a.cfg.KafkaTopic)
+	security := kafka.SecurityConfig{
+		SASLMechanism:      a.cfg.KafkaSecurity.SASLMechanism,
+		Username:           a.cfg.KafkaSecurity.Username,
+		Password:           a.cfg.KafkaSecurity.Password,
+		TLSEnabled:         a.cfg.KafkaSecurity.TLSEnabled,
+		CAFile:             a.cfg.KafkaSecurity.CAFile,
+		CertFile:           a.cfg.KafkaSecurity.CertFile,
+		KeyFile:            a.cfg.KafkaSecurity.KeyFile,
+		InsecureSkipVerify: a.cfg.KafkaSecurity.InsecureSkipVerify,
+		OAuth2: kafka.OAuth2Config{
+			TokenURL:     a.cfg.KafkaSecurity.OAuth2.TokenURL,
+			ClientID:     a.cfg.KafkaSecurity.OAuth2.ClientID,
+			ClientSecret: a.cfg.KafkaSecurity.OAuth2.ClientSecret,
+			Scopes:       a.cfg.KafkaSecurity.OAuth2.Scopes,
+		},
+	}
+	producer, err := kafka.NewProducer(a.cfg.KafkaBrokers, a.cfg.KafkaTopic, security)
+	if err != nil {
+		appErr := customerrors.ErrServiceUnavailable.WithDetails("failed to set up Kafka producer authentication: " + err.Error())
+		logger.Error("Failed to create Kafka producer", "error", appErr)
+		return appErr
+	}
+	a.producer = producer
 	if a.cfg.MessageFormat == "protobuf" {
 		a.producer.SetMessageFormat(kafka.FormatProtobuf)
 	}
+	if a.tokenSource != nil {
+		a.producer.SetSASL(auth.NewSASLMechanism(a.tokenSource))
+	}
 	a.apiServer.SetProducer(a.producer)
+	a.apiServer.SetJobTracker(jobTracker)
+	a.apiServer.SetConsumerStateFunc(a.handler.ReadyState)
 
 	// Setup HTTP server with chi router
 	mux := http.NewServeMux()
@@ -102,6 +210,54 @@ func (a *Application) Init() error {
 	return nil
 }
 
+// newImagePuller builds the multi-provider puller used by the real processor
+// to fetch image sources, backed by an on-disk cache. The s3/minio provider
+// is only registered when object storage is configured, since it reuses the
+// same backend as NewStorage.
+func (a *Application) newImagePuller(ctx context.Context) (puller.Puller, error) {
+	cache, err := puller.NewDiskCache(a.cfg.PullerCacheDir, a.cfg.PullerCacheMaxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create puller cache: %w", err)
+	}
+
+	var httpClient *http.Client
+	if a.tokenSource != nil {
+		httpClient = auth.HTTPClient(a.tokenSource)
+	}
+
+	providers := []puller.Provider{
+		puller.NewHTTPProvider("http", httpClient),
+		puller.NewHTTPProvider("https", httpClient),
+		puller.NewFileProvider(),
+	}
+
+	if a.cfg.StorageBackend == "s3" {
+		store, err := NewStorage(ctx, a.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 storage for puller: %w", err)
+		}
+		providers = append(providers,
+			puller.NewS3Provider("s3", store),
+			puller.NewS3Provider("minio", store),
+		)
+	}
+
+	return puller.New(cache, providers...), nil
+}
+
+// kafkaDialer builds a *kafka.Dialer authenticated with SASL OAUTHBEARER when
+// OAuth2 is configured, or nil to fall back to kafka-go's default dialer.
+func (a *Application) kafkaDialer() *kafkago.Dialer {
+	if a.tokenSource == nil {
+		return nil
+	}
+	return &kafkago.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		SASLMechanism: auth.NewSASLMechanism(a.tokenSource),
+	}
+}
+
 // Run starts the application and blocks until shutdown
 func (a *Application) Run(ctx context.Context) error {
 	// Setup context with cancellation for graceful shutdown
@@ -166,6 +322,20 @@ func (a *Application) Shutdown() error {
 		}
 	}
 
+	// Close DLQ producer
+	if a.dlqProducer != nil {
+		if err := a.dlqProducer.Close(); err != nil {
+			logger.Error("DLQ producer close error", "error", err)
+		}
+	}
+
+	// Flush and shut down the trace exporter
+	if a.shutdownTracing != nil {
+		if err := a.shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("Tracing shutdown error", "error", err)
+		}
+	}
+
 	logger.Info("Application stopped successfully")
 	return nil
 }
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"kafka-consumer/internal/config"
+	"kafka-consumer/internal/pkg/logger"
+	"kafka-consumer/internal/repository/storage"
+)
+
+// NewStorage builds the Storage implementation selected by cfg.StorageBackend,
+// so the processor can consume local disk or S3-compatible object storage transparently.
+func NewStorage(ctx context.Context, cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageBackend {
+	case "", "local":
+		logger.Info("Using local filesystem storage", "output_dir", cfg.OutputDir)
+		return storage.NewLocalStorage(cfg.OutputDir), nil
+	case "s3":
+		logger.Info("Using S3 storage", "bucket", cfg.S3Bucket, "endpoint", cfg.S3Endpoint)
+		return storage.NewS3Storage(ctx, storage.S3Config{
+			Bucket:            cfg.S3Bucket,
+			Region:            cfg.S3Region,
+			Endpoint:          cfg.S3Endpoint,
+			AccessKeyID:       cfg.S3AccessKeyID,
+			SecretAccessKey:   cfg.S3SecretAccessKey,
+			UseSSL:            cfg.S3UseSSL,
+			ServerSideEncrypt: cfg.S3ServerSideEncrypt,
+			PartSize:          uint64(cfg.S3PartSizeMB) * 1024 * 1024,
+			UploadConcurrency: cfg.S3UploadConcurrency,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %q", cfg.StorageBackend)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,18 +15,123 @@ type Config struct {
 	KafkaTopic   string   `mapstructure:"kafka_topic"`
 	KafkaGroupID string   `mapstructure:"kafka_group_id"`
 
+	// Architecture selects which cmd/consumer implementation runs: "legacy"
+	// (the original internal/kafka.Consumer + internal/api stack) or "new"
+	// (internal/app.Application, built on internal/delivery/queue.Handler
+	// and internal/delivery/api.Server). Defaults to "legacy" so existing
+	// deployments are unaffected; switch to "new" to cut over.
+	Architecture string `mapstructure:"architecture"`
+
 	// Processor settings
 	ProcessorMode string `mapstructure:"processor_mode"` // "stub" or "real"
 	OutputDir     string `mapstructure:"output_dir"`     // Directory for processed images
 
+	// PipelineMaxSteps caps how many steps a COMMAND_TYPE_PIPELINE command
+	// may chain in a single request, to bound processing time and memory.
+	PipelineMaxSteps int `mapstructure:"pipeline_max_steps"`
+
 	// Message format
-	MessageFormat string `mapstructure:"message_format"` // "json" or "protobuf"
+	MessageFormat string `mapstructure:"message_format"` // "json", "protobuf" or "schema_registry"
+
+	// Schema Registry settings (used when MessageFormat is "schema_registry")
+	SchemaRegistryURL             string        `mapstructure:"schema_registry_url"`
+	SchemaRegistryUsername        string        `mapstructure:"schema_registry_username"`
+	SchemaRegistryPassword        string        `mapstructure:"schema_registry_password"`
+	SchemaRegistrySubjectStrategy string        `mapstructure:"schema_registry_subject_strategy"`
+	SchemaRegistryCacheTTL        time.Duration `mapstructure:"schema_registry_cache_ttl"`
 
 	// HTTP settings
 	HTTPPort string `mapstructure:"http_port"`
 
 	// Logging
 	LogLevel string `mapstructure:"log_level"`
+
+	// Dead-letter / retry settings
+	DLQTopic            string        `mapstructure:"dlq_topic"`
+	RetryMaxAttempts    int           `mapstructure:"retry_max_attempts"`
+	RetryInitialBackoff time.Duration `mapstructure:"retry_initial_backoff"`
+	RetryMaxBackoff     time.Duration `mapstructure:"retry_max_backoff"`
+
+	// Dead-letter / retry settings for the legacy internal/kafka.Consumer
+	// path (cmd/consumer), kept separate from the settings above, which
+	// configure internal/delivery/queue.Handler. Both paths retry in-process.
+	KafkaDLQTopic    string        `mapstructure:"kafka_dlq_topic"`
+	MaxRetries       int           `mapstructure:"max_retries"`
+	RetryBaseBackoff time.Duration `mapstructure:"retry_base_backoff"`
+
+	// Storage settings
+	StorageBackend      string `mapstructure:"storage_backend"` // "local" or "s3"
+	S3Bucket            string `mapstructure:"s3_bucket"`
+	S3Region            string `mapstructure:"s3_region"`
+	S3Endpoint          string `mapstructure:"s3_endpoint"`
+	S3AccessKeyID       string `mapstructure:"s3_access_key_id"`
+	S3SecretAccessKey   string `mapstructure:"s3_secret_access_key"`
+	S3UseSSL            bool   `mapstructure:"s3_use_ssl"`
+	S3ServerSideEncrypt bool   `mapstructure:"s3_server_side_encrypt"`
+	S3PartSizeMB        int64  `mapstructure:"s3_part_size_mb"`
+	S3UploadConcurrency int    `mapstructure:"s3_upload_concurrency"`
+
+	// Puller settings (image source fetching: http(s)/file/s3/minio)
+	PullerCacheDir      string `mapstructure:"puller_cache_dir"`
+	PullerCacheMaxBytes int64  `mapstructure:"puller_cache_max_bytes"`
+
+	// OAuth2 client-credentials settings, used for Kafka SASL OAUTHBEARER
+	// and outbound HTTP calls. Left empty to disable (the default).
+	OAuthTokenURL     string   `mapstructure:"oauth_token_url"`
+	OAuthClientID     string   `mapstructure:"oauth_client_id"`
+	OAuthClientSecret string   `mapstructure:"oauth_client_secret"`
+	OAuthScopes       []string `mapstructure:"oauth_scopes"`
+	OAuthAudience     string   `mapstructure:"oauth_audience"`
+
+	// Distributed tracing settings, wired into internal/pkg/tracing.Config.
+	// Keys follow the OpenTelemetry env var convention (OTEL_TRACES_EXPORTER,
+	// OTEL_EXPORTER_OTLP_ENDPOINT, etc.) so they can be overridden without
+	// any extra mapping beyond viper's automatic env lookup.
+	TracingExporter     string            `mapstructure:"otel_traces_exporter"`
+	TracingEndpoint     string            `mapstructure:"otel_exporter_otlp_endpoint"`
+	TracingHeaders      map[string]string `mapstructure:"otel_exporter_otlp_headers"`
+	TracingInsecure     bool              `mapstructure:"otel_exporter_otlp_insecure"`
+	TracingSamplerRatio float64           `mapstructure:"otel_traces_sampler_arg"`
+
+	// KafkaSecurity configures SASL/TLS authentication for Kafka
+	// connections. Left at its zero value (SASLMechanism "") to keep
+	// plaintext, unauthenticated connections as the default.
+	KafkaSecurity KafkaSecurityConfig
+
+	// WebhookSecret signs job-completion webhook payloads with HMAC-SHA256.
+	// Left empty to disable webhook delivery even when a request sets
+	// callback_url.
+	WebhookSecret string `mapstructure:"webhook_secret"`
+}
+
+// KafkaSecurityConfig configures SASL authentication and TLS for Kafka
+// Reader/Writer connections.
+type KafkaSecurityConfig struct {
+	// SASLMechanism is one of "" (disabled), "plain", "scram-sha-256",
+	// "scram-sha-512" or "oauthbearer".
+	SASLMechanism string `mapstructure:"kafka_sasl_mechanism"`
+	Username      string `mapstructure:"kafka_sasl_username"`
+	Password      string `mapstructure:"kafka_sasl_password"`
+
+	TLSEnabled         bool   `mapstructure:"kafka_tls_enabled"`
+	CAFile             string `mapstructure:"kafka_tls_ca_file"`
+	CertFile           string `mapstructure:"kafka_tls_cert_file"`
+	KeyFile            string `mapstructure:"kafka_tls_key_file"`
+	InsecureSkipVerify bool   `mapstructure:"kafka_tls_insecure_skip_verify"`
+
+	// OAuth2 is used when SASLMechanism is "oauthbearer".
+	OAuth2 KafkaOAuth2Config
+}
+
+// KafkaOAuth2Config holds the client-credentials settings for the
+// OAUTHBEARER SASL mechanism, kept separate from the top-level OAuth*
+// fields above since those authenticate outbound HTTP calls and the
+// delivery/queue path's dialer, not necessarily the same identity provider.
+type KafkaOAuth2Config struct {
+	TokenURL     string   `mapstructure:"kafka_oauth_token_url"`
+	ClientID     string   `mapstructure:"kafka_oauth_client_id"`
+	ClientSecret string   `mapstructure:"kafka_oauth_client_secret"`
+	Scopes       []string `mapstructure:"kafka_oauth_scopes"`
 }
 
 // LoadConfig loads configuration from environment variables and config files
@@ -33,14 +139,40 @@ func LoadConfig() *Config {
 	v := viper.New()
 
 	// Set defaults
+	v.SetDefault("architecture", "legacy")
 	v.SetDefault("kafka_brokers", "localhost:9092")
 	v.SetDefault("kafka_topic", "image-commands")
 	v.SetDefault("kafka_group_id", "image-processor-group")
 	v.SetDefault("processor_mode", "stub")
 	v.SetDefault("output_dir", "/tmp/processed-images")
+	v.SetDefault("pipeline_max_steps", 10)
 	v.SetDefault("message_format", "json")
+	v.SetDefault("schema_registry_subject_strategy", "topic_name")
+	v.SetDefault("schema_registry_cache_ttl", 10*time.Minute)
 	v.SetDefault("http_port", "8080")
 	v.SetDefault("log_level", "info")
+	v.SetDefault("dlq_topic", "image-commands-dlq")
+	v.SetDefault("retry_max_attempts", 3)
+	v.SetDefault("retry_initial_backoff", 500*time.Millisecond)
+	v.SetDefault("retry_max_backoff", 30*time.Second)
+	v.SetDefault("kafka_dlq_topic", "image-commands-dlq")
+	v.SetDefault("max_retries", 3)
+	v.SetDefault("retry_base_backoff", 500*time.Millisecond)
+	v.SetDefault("storage_backend", "local")
+	v.SetDefault("s3_region", "us-east-1")
+	v.SetDefault("s3_use_ssl", true)
+	v.SetDefault("s3_server_side_encrypt", false)
+	v.SetDefault("s3_part_size_mb", 64)
+	v.SetDefault("s3_upload_concurrency", 4)
+	v.SetDefault("puller_cache_dir", "/tmp/puller-cache")
+	v.SetDefault("puller_cache_max_bytes", 1<<30) // 1GB
+	v.SetDefault("otel_traces_exporter", "stdout")
+	v.SetDefault("otel_exporter_otlp_endpoint", "localhost:4317")
+	v.SetDefault("otel_exporter_otlp_insecure", true)
+	v.SetDefault("otel_traces_sampler_arg", 1.0)
+	v.SetDefault("kafka_sasl_mechanism", "")
+	v.SetDefault("kafka_tls_enabled", false)
+	v.SetDefault("webhook_secret", "")
 
 	// Read from environment variables
 	v.AutomaticEnv()
@@ -62,15 +194,77 @@ func LoadConfig() *Config {
 	cfg := &Config{}
 
 	// Manually bind because Viper env binding requires explicit mapping
+	cfg.Architecture = v.GetString("architecture")
 	cfg.KafkaBrokers = parseBrokers(v.GetString("kafka_brokers"))
 	cfg.KafkaTopic = v.GetString("kafka_topic")
 	cfg.KafkaGroupID = v.GetString("kafka_group_id")
 	cfg.ProcessorMode = v.GetString("processor_mode")
 	cfg.OutputDir = v.GetString("output_dir")
+	cfg.PipelineMaxSteps = v.GetInt("pipeline_max_steps")
 	cfg.MessageFormat = v.GetString("message_format")
+
+	cfg.SchemaRegistryURL = v.GetString("schema_registry_url")
+	cfg.SchemaRegistryUsername = v.GetString("schema_registry_username")
+	cfg.SchemaRegistryPassword = v.GetString("schema_registry_password")
+	cfg.SchemaRegistrySubjectStrategy = v.GetString("schema_registry_subject_strategy")
+	cfg.SchemaRegistryCacheTTL = v.GetDuration("schema_registry_cache_ttl")
 	cfg.HTTPPort = v.GetString("http_port")
 	cfg.LogLevel = v.GetString("log_level")
 
+	cfg.DLQTopic = v.GetString("dlq_topic")
+	cfg.RetryMaxAttempts = v.GetInt("retry_max_attempts")
+	cfg.RetryInitialBackoff = v.GetDuration("retry_initial_backoff")
+	cfg.RetryMaxBackoff = v.GetDuration("retry_max_backoff")
+
+	cfg.KafkaDLQTopic = v.GetString("kafka_dlq_topic")
+	cfg.MaxRetries = v.GetInt("max_retries")
+	cfg.RetryBaseBackoff = v.GetDuration("retry_base_backoff")
+
+	cfg.StorageBackend = v.GetString("storage_backend")
+	cfg.S3Bucket = v.GetString("s3_bucket")
+	cfg.S3Region = v.GetString("s3_region")
+	cfg.S3Endpoint = v.GetString("s3_endpoint")
+	cfg.S3AccessKeyID = v.GetString("s3_access_key_id")
+	cfg.S3SecretAccessKey = v.GetString("s3_secret_access_key")
+	cfg.S3UseSSL = v.GetBool("s3_use_ssl")
+	cfg.S3ServerSideEncrypt = v.GetBool("s3_server_side_encrypt")
+	cfg.S3PartSizeMB = v.GetInt64("s3_part_size_mb")
+	cfg.S3UploadConcurrency = v.GetInt("s3_upload_concurrency")
+
+	cfg.PullerCacheDir = v.GetString("puller_cache_dir")
+	cfg.PullerCacheMaxBytes = v.GetInt64("puller_cache_max_bytes")
+
+	cfg.OAuthTokenURL = v.GetString("oauth_token_url")
+	cfg.OAuthClientID = v.GetString("oauth_client_id")
+	cfg.OAuthClientSecret = v.GetString("oauth_client_secret")
+	cfg.OAuthScopes = v.GetStringSlice("oauth_scopes")
+	cfg.OAuthAudience = v.GetString("oauth_audience")
+
+	cfg.TracingExporter = v.GetString("otel_traces_exporter")
+	cfg.TracingEndpoint = v.GetString("otel_exporter_otlp_endpoint")
+	cfg.TracingHeaders = parseHeaders(v.GetString("otel_exporter_otlp_headers"))
+	cfg.TracingInsecure = v.GetBool("otel_exporter_otlp_insecure")
+	cfg.TracingSamplerRatio = v.GetFloat64("otel_traces_sampler_arg")
+
+	cfg.KafkaSecurity = KafkaSecurityConfig{
+		SASLMechanism:      v.GetString("kafka_sasl_mechanism"),
+		Username:           v.GetString("kafka_sasl_username"),
+		Password:           v.GetString("kafka_sasl_password"),
+		TLSEnabled:         v.GetBool("kafka_tls_enabled"),
+		CAFile:             v.GetString("kafka_tls_ca_file"),
+		CertFile:           v.GetString("kafka_tls_cert_file"),
+		KeyFile:            v.GetString("kafka_tls_key_file"),
+		InsecureSkipVerify: v.GetBool("kafka_tls_insecure_skip_verify"),
+		OAuth2: KafkaOAuth2Config{
+			TokenURL:     v.GetString("kafka_oauth_token_url"),
+			ClientID:     v.GetString("kafka_oauth_client_id"),
+			ClientSecret: v.GetString("kafka_oauth_client_secret"),
+			Scopes:       v.GetStringSlice("kafka_oauth_scopes"),
+		},
+	}
+
+	cfg.WebhookSecret = v.GetString("webhook_secret")
+
 	return cfg
 }
 
@@ -81,3 +275,20 @@ func parseBrokers(brokers string) []string {
 	}
 	return strings.Split(brokers, ",")
 }
+
+// parseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS convention of
+// comma-separated "key=value" pairs into a map, skipping malformed entries.
+func parseHeaders(headers string) map[string]string {
+	if headers == "" {
+		return nil
+	}
+	result := make(map[string]string)
+	for _, pair := range strings.Split(headers, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result
+}
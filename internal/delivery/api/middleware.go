@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"kafka-consumer/internal/pkg/logger"
+)
+
+// RequestLogger must run after chi's middleware.RequestID. It echoes the
+// generated/propagated request ID back as X-Request-ID and stashes a
+// request-scoped logger carrying it in the request context, so every log
+// line produced while handling the request can be filtered by that ID.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqID := middleware.GetReqID(r.Context())
+		w.Header().Set("X-Request-ID", reqID)
+
+		log := logger.FromContext(r.Context()).With("request_id", reqID)
+		ctx := logger.WithContext(r.Context(), log)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
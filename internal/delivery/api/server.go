@@ -6,16 +6,19 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"sync/atomic"
 	"time"
-	"log"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/attribute"
 
+	"kafka-consumer/internal/jobs"
 	"kafka-consumer/internal/models/api"
 	"kafka-consumer/internal/pkg/customerrors"
+	"kafka-consumer/internal/pkg/logger"
+	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/pkg/tracing"
 	pb "kafka-consumer/proto"
 )
 
@@ -24,11 +27,17 @@ type MessageSender interface {
 	SendMessage(ctx context.Context, cmd *pb.ImageCommand) error
 }
 
+// ConsumerStateFunc reports the current state of the Kafka consumer (e.g.
+// "running", "reconnecting") and whether it should be considered ready to
+// serve traffic.
+type ConsumerStateFunc func() (state string, ready bool)
+
 // Server represents the HTTP API server for health checks and message sending
 type Server struct {
 	startTime     time.Time
-	messagesCount int64
 	producer      MessageSender
+	jobTracker    *jobs.Tracker
+	consumerState ConsumerStateFunc
 	validate      *validator.Validate
 	router        chi.Router
 }
@@ -49,6 +58,7 @@ func (s *Server) setupRouter() {
 
 	// Middleware
 	r.Use(middleware.RequestID)
+	r.Use(RequestLogger)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(30 * time.Second))
@@ -58,6 +68,8 @@ func (s *Server) setupRouter() {
 	r.Get("/ready", s.ReadyHandler)
 	r.Get("/stats", s.StatsHandler)
 	r.Post("/send", s.SendHandler)
+	r.Get("/jobs/{id}", s.JobHandler)
+	r.Get("/jobs", s.JobsHandler)
 
 	s.router = r
 }
@@ -72,14 +84,32 @@ func (s *Server) SetProducer(producer MessageSender) {
 	s.producer = producer
 }
 
-// IncrementMessagesCount increments the processed messages counter
+// SetJobTracker sets the tracker used to record job lifecycle state for
+// SendHandler and to look up job status for /jobs endpoints. Without it,
+// /send still works but doesn't record a job, and /jobs/{id} and /jobs
+// report 404/empty.
+func (s *Server) SetJobTracker(tracker *jobs.Tracker) {
+	s.jobTracker = tracker
+}
+
+// SetConsumerStateFunc wires the Kafka consumer's state into ReadyHandler
+// and StatsHandler, so readiness flips false while the consumer is
+// reconnecting. Without it, both handlers behave as if no consumer exists.
+func (s *Server) SetConsumerStateFunc(fn ConsumerStateFunc) {
+	s.consumerState = fn
+}
+
+// IncrementMessagesCount records a successful message send for callers that
+// don't go through SendHandler (e.g. direct producer use).
 func (s *Server) IncrementMessagesCount() {
-	atomic.AddInt64(&s.messagesCount, 1)
+	metrics.RecordAPISendRequest("unknown", "success")
 }
 
-// GetMessagesCount returns the current messages count
+// GetMessagesCount returns the number of messages sent successfully, read
+// from the api_send_requests_total Prometheus counter so the JSON stats and
+// a Prometheus scrape always agree.
 func (s *Server) GetMessagesCount() int64 {
-	return atomic.LoadInt64(&s.messagesCount)
+	return metrics.TotalAPISendRequests()
 }
 
 // HealthHandler handles health check requests
@@ -97,6 +127,15 @@ func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
 // ReadyHandler handles readiness check requests
 func (s *Server) ReadyHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+
+	if s.consumerState != nil {
+		if state, ready := s.consumerState(); !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(api.ReadyResponse{Status: "not_ready", Reason: "kafka consumer state: " + state})
+			return
+		}
+	}
+
 	json.NewEncoder(w).Encode(api.ReadyResponse{Status: "ready"})
 }
 
@@ -106,6 +145,9 @@ func (s *Server) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		UptimeSeconds:     time.Since(s.startTime).Seconds(),
 		MessagesProcessed: s.GetMessagesCount(),
 	}
+	if s.consumerState != nil {
+		stats.ConsumerState, _ = s.consumerState()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
@@ -118,9 +160,10 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 		customerrors.WriteError(w, customerrors.ErrBadRequest.WithDetails("Invalid JSON: "+err.Error()))
 		return
 	}
-	log.Printf("ðŸ“¥ API Received: ID=%s, Command=%s, URL=%s, HasParams=%v, Params=%+v",
-		req.ID, req.Command, req.ImageURL, 
-		req.Parameters != nil, req.Parameters)
+	log := logger.FromContext(r.Context())
+	log.Info("API received send request",
+		"id", req.ID, "command", req.Command, "image_url", req.ImageURL,
+		"has_params", req.Parameters != nil, "params", req.Parameters)
 
 	// Validate request using validator
 	if err := s.validate.Struct(req); err != nil {
@@ -128,6 +171,9 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, span := tracing.StartSpan(r.Context(), "SendHandler")
+	defer span.End()
+
 	// Convert to protobuf command
 	cmd := &pb.ImageCommand{
 		Id:       req.ID,
@@ -137,6 +183,7 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 	// Map command type and parameters
 	switch req.Command {
 	case "resize":
+		tracing.SetAttributes(ctx, attribute.String("command.type", "resize"))
 		cmd.Command = pb.CommandType_COMMAND_TYPE_RESIZE
 		if req.Parameters != nil {
 			params := &pb.ResizeParameters{}
@@ -149,6 +196,7 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 			cmd.Parameters = &pb.ImageCommand_Resize{Resize: params}
 		}
 	case "filter":
+		tracing.SetAttributes(ctx, attribute.String("command.type", "filter"))
 		cmd.Command = pb.CommandType_COMMAND_TYPE_FILTER
 		if req.Parameters != nil {
 			params := &pb.FilterParameters{}
@@ -161,6 +209,7 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 			cmd.Parameters = &pb.ImageCommand_Filter{Filter: params}
 		}
 	case "transform":
+		tracing.SetAttributes(ctx, attribute.String("command.type", "transform"))
 		cmd.Command = pb.CommandType_COMMAND_TYPE_TRANSFORM
 		if req.Parameters != nil {
 			params := &pb.TransformParameters{}
@@ -176,6 +225,7 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 			cmd.Parameters = &pb.ImageCommand_Transform{Transform: params}
 		}
 	case "analyze":
+		tracing.SetAttributes(ctx, attribute.String("command.type", "analyze"))
 		cmd.Command = pb.CommandType_COMMAND_TYPE_ANALYZE
 		if req.Parameters != nil {
 			params := &pb.AnalyzeParameters{}
@@ -189,6 +239,7 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 			cmd.Parameters = &pb.ImageCommand_Analyze{Analyze: params}
 		}
 	case "crop":
+		tracing.SetAttributes(ctx, attribute.String("command.type", "crop"))
 		cmd.Command = pb.CommandType_COMMAND_TYPE_CROP
 		if req.Parameters != nil {
 			params := &pb.CropParameters{}
@@ -207,6 +258,7 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 			cmd.Parameters = &pb.ImageCommand_Crop{Crop: params}
 		}
 	case "remove_background":
+		tracing.SetAttributes(ctx, attribute.String("command.type", "remove_background"))
 		cmd.Command = pb.CommandType_COMMAND_TYPE_REMOVE_BACKGROUND
 		if req.Parameters != nil {
 			params := &pb.RemoveBackgroundParameters{}
@@ -223,24 +275,95 @@ func (s *Server) SendHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("ðŸ“¤ Sending to Kafka: ID=%s, Command=%v, HasProtoParams=%v",
-		cmd.Id, cmd.Command, cmd.Parameters != nil)
+	log.Info("Sending command to Kafka",
+		"id", cmd.Id, "command", cmd.Command, "has_proto_params", cmd.Parameters != nil)
 
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	if err := s.jobTracker.Create(&jobs.Job{
+		ID:          req.ID,
+		Status:      jobs.StatusQueued,
+		Command:     req.Command,
+		CallbackURL: req.CallbackURL,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		logger.Error("Failed to create job record", "id", req.ID, "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if err := s.producer.SendMessage(ctx, cmd); err != nil {
+	sendStart := time.Now()
+	err := s.producer.SendMessage(ctx, cmd)
+	metrics.ObserveAPISendDuration(req.Command, time.Since(sendStart).Seconds())
+
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		metrics.RecordAPISendRequest(req.Command, "error")
+		s.jobTracker.MarkFailed(req.ID, err.Error())
 		customerrors.WriteError(w, customerrors.ErrInternal.WithDetails("Failed to send message: "+err.Error()))
 		return
 	}
+	metrics.RecordAPISendRequest(req.Command, "success")
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("Location", "/jobs/"+req.ID)
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(api.SendMessageResponse{
 		Success: true,
-		Message: "Message sent successfully",
+		Message: "Message queued successfully",
 		ID:      req.ID,
 	})
-	s.IncrementMessagesCount()
+}
+
+// JobHandler returns the current status of a single job.
+func (s *Server) JobHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if s.jobTracker == nil {
+		customerrors.WriteError(w, customerrors.ErrNotFound.WithDetails("Job not found: "+id))
+		return
+	}
+
+	job, ok := s.jobTracker.Get(id)
+	if !ok {
+		customerrors.WriteError(w, customerrors.ErrNotFound.WithDetails("Job not found: "+id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobStatusResponse(job))
+}
+
+// JobsHandler lists jobs, optionally filtered by the ?status= query param.
+func (s *Server) JobsHandler(w http.ResponseWriter, r *http.Request) {
+	status := jobs.Status(r.URL.Query().Get("status"))
+
+	var list []*jobs.Job
+	if s.jobTracker != nil {
+		list = s.jobTracker.List(status)
+	}
+
+	responses := make([]api.JobStatusResponse, 0, len(list))
+	for _, job := range list {
+		responses = append(responses, jobStatusResponse(job))
+	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// jobStatusResponse converts a jobs.Job to its wire representation.
+func jobStatusResponse(job *jobs.Job) api.JobStatusResponse {
+	resp := api.JobStatusResponse{
+		ID:     job.ID,
+		Status: string(job.Status),
+		Result: job.Result,
+		Error:  job.Error,
+	}
+	if !job.StartedAt.IsZero() {
+		resp.StartedAt = job.StartedAt.Format(time.RFC3339)
+	}
+	if !job.FinishedAt.IsZero() {
+		resp.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return resp
 }
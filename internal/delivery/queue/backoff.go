@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryConfig controls how many times a failed message is retried and how
+// long the handler waits between attempts before giving up to the DLQ.
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryConfig returns sane defaults for local/dev use
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// backoffDuration returns an exponential backoff with +/-20% jitter for the
+// given attempt number (1-indexed), capped at MaxBackoff.
+func (r RetryConfig) backoffDuration(attempt int) time.Duration {
+	d := r.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d > r.MaxBackoff {
+			d = r.MaxBackoff
+			break
+		}
+	}
+
+	jitter := 0.8 + rand.Float64()*0.4 // 0.8x - 1.2x
+	return time.Duration(float64(d) * jitter)
+}
+
+// SimpleBackoff tracks a progressively increasing delay for a failing
+// operation, borrowing the auto-reconnect pattern from Kafka client
+// libraries like goka: start at Initial, multiply by Factor on every
+// failure up to Max, and Reset back to Initial as soon as something
+// succeeds. Unlike RetryConfig, which computes a stateless delay per
+// attempt number, a SimpleBackoff carries its own state across calls so the
+// caller doesn't have to track an attempt counter itself.
+type SimpleBackoff struct {
+	Initial time.Duration
+	Factor  float64
+	Max     time.Duration
+
+	current time.Duration
+}
+
+// Next returns the delay to wait before the next attempt and advances the
+// backoff in preparation for the attempt after that.
+func (b *SimpleBackoff) Next() time.Duration {
+	if b.current <= 0 {
+		b.current = b.Initial
+	}
+
+	d := b.current
+	next := time.Duration(float64(b.current) * b.Factor)
+	if next > b.Max {
+		next = b.Max
+	}
+	b.current = next
+	return d
+}
+
+// Reset clears the accumulated backoff so the next failure starts again at Initial.
+func (b *SimpleBackoff) Reset() {
+	b.current = 0
+}
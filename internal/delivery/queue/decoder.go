@@ -1,25 +1,40 @@
 package queue
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 
-	pb "kafka-consumer/proto"
-
+	"github.com/linkedin/goavro/v2"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"kafka-consumer/internal/pkg/logger"
+	"kafka-consumer/internal/pkg/schemaregistry"
+	pb "kafka-consumer/proto"
 )
 
 // MessageFormat represents the format of Kafka messages
 type MessageFormat string
 
 const (
-	FormatJSON     MessageFormat = "json"
-	FormatProtobuf MessageFormat = "protobuf"
+	FormatJSON           MessageFormat = "json"
+	FormatProtobuf       MessageFormat = "protobuf"
+	FormatSchemaRegistry MessageFormat = "schema_registry"
 )
 
+// confluentMagicByte is the leading byte of the Confluent wire format, which
+// is followed by a 4-byte big-endian schema ID.
+const confluentMagicByte = 0x00
+
 // Decoder implements MessageDecoder interface
 type Decoder struct {
 	preferredFormat MessageFormat
+	registry        *schemaregistry.Client
 }
 
 // NewDecoder creates a new message decoder
@@ -29,8 +44,21 @@ func NewDecoder(format MessageFormat) *Decoder {
 	}
 }
 
+// NewSchemaRegistryDecoder creates a decoder that resolves schemas via a
+// Confluent-compatible Schema Registry before decoding each message.
+func NewSchemaRegistryDecoder(registry *schemaregistry.Client) *Decoder {
+	return &Decoder{
+		preferredFormat: FormatSchemaRegistry,
+		registry:        registry,
+	}
+}
+
 // DecodeCommand decodes a message payload into an ImageCommand
-func (d *Decoder) DecodeCommand(data []byte) (interface{}, error) {
+func (d *Decoder) DecodeCommand(ctx context.Context, data []byte) (interface{}, error) {
+	if d.preferredFormat == FormatSchemaRegistry {
+		return d.decodeSchemaRegistry(ctx, data)
+	}
+
 	if d.preferredFormat == FormatProtobuf {
 		cmd := &pb.ImageCommand{}
 		if err := proto.Unmarshal(data, cmd); err == nil {
@@ -54,6 +82,127 @@ func (d *Decoder) DecodeCommand(data []byte) (interface{}, error) {
 	return pbCmd, nil
 }
 
+// decodeSchemaRegistry reads the Confluent wire-format prefix, resolves the
+// writer schema from the registry, decodes the payload with it, and
+// normalizes the result to *pb.ImageCommand.
+func (d *Decoder) decodeSchemaRegistry(ctx context.Context, data []byte) (*pb.ImageCommand, error) {
+	if d.registry == nil {
+		return nil, fmt.Errorf("schema registry decoder used without a configured client")
+	}
+	if len(data) < 5 || data[0] != confluentMagicByte {
+		return nil, fmt.Errorf("payload is missing the Confluent wire-format prefix")
+	}
+
+	schemaID := int(binary.BigEndian.Uint32(data[1:5]))
+	payload := data[5:]
+
+	schema, err := d.registry.GetByID(ctx, schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema %d: %w", schemaID, err)
+	}
+	logger.FromContext(ctx).Debug("Resolved schema registry writer schema", "schema_id", schemaID, "schema_type", schema.Type)
+
+	switch schema.Type {
+	case schemaregistry.SchemaTypeProtobuf:
+		return decodeDynamicProtobuf(schema.Raw, payload)
+	case schemaregistry.SchemaTypeAvro, "":
+		return decodeAvro(schema.Raw, payload)
+	default:
+		return nil, fmt.Errorf("unsupported schema type: %s", schema.Type)
+	}
+}
+
+// decodeDynamicProtobuf unmarshals payload using a dynamicpb.Message built
+// from the registry's FileDescriptorProto, then re-marshals it into the
+// concrete *pb.ImageCommand type (the two are wire-compatible).
+// rawDescriptor must be a serialized FileDescriptorProto, the form
+// kafka.Producer registers; a schema registered as .proto source text fails
+// to parse here.
+func decodeDynamicProtobuf(rawDescriptor string, payload []byte) (*pb.ImageCommand, error) {
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal([]byte(rawDescriptor), fd); err != nil {
+		return nil, fmt.Errorf("failed to parse protobuf schema descriptor: %w", err)
+	}
+
+	if len(fd.GetMessageType()) == 0 {
+		return nil, fmt.Errorf("schema descriptor has no message types")
+	}
+
+	file, err := protodesc.NewFile(fd, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build file descriptor: %w", err)
+	}
+	msgDesc := file.Messages().Get(0)
+
+	dynMsg := dynamicpb.NewMessage(msgDesc)
+	if err := proto.Unmarshal(payload, dynMsg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dynamic protobuf message: %w", err)
+	}
+
+	wire, err := proto.Marshal(dynMsg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-marshal dynamic message: %w", err)
+	}
+
+	cmd := &pb.ImageCommand{}
+	if err := proto.Unmarshal(wire, cmd); err != nil {
+		return nil, fmt.Errorf("failed to normalize dynamic message to ImageCommand: %w", err)
+	}
+	return cmd, nil
+}
+
+// decodeAvro decodes an Avro-encoded payload into a map and maps its id,
+// image_url and command fields onto *pb.ImageCommand. Unlike decodeJSON, it
+// does not map command parameters.
+func decodeAvro(rawSchema string, payload []byte) (*pb.ImageCommand, error) {
+	codec, err := goavro.NewCodec(rawSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+
+	native, _, err := codec.NativeFromBinary(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode avro payload: %w", err)
+	}
+
+	fields, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected avro decode result type %T", native)
+	}
+
+	cmd := &pb.ImageCommand{}
+	if id, ok := fields["id"].(string); ok {
+		cmd.Id = id
+	}
+	if url, ok := fields["image_url"].(string); ok {
+		cmd.ImageUrl = url
+	}
+	if command, ok := fields["command"].(string); ok {
+		cmd.Command = commandTypeFromString(command)
+	}
+
+	return cmd, nil
+}
+
+func commandTypeFromString(command string) pb.CommandType {
+	switch command {
+	case "resize":
+		return pb.CommandType_COMMAND_TYPE_RESIZE
+	case "filter":
+		return pb.CommandType_COMMAND_TYPE_FILTER
+	case "transform":
+		return pb.CommandType_COMMAND_TYPE_TRANSFORM
+	case "analyze":
+		return pb.CommandType_COMMAND_TYPE_ANALYZE
+	case "crop":
+		return pb.CommandType_COMMAND_TYPE_CROP
+	case "remove_background":
+		return pb.CommandType_COMMAND_TYPE_REMOVE_BACKGROUND
+	default:
+		return pb.CommandType_COMMAND_TYPE_UNSPECIFIED
+	}
+}
+
 // decodeJSON decodes JSON message to protobuf command
 func (d *Decoder) decodeJSON(data []byte) (*pb.ImageCommand, error) {
 	var jsonCmd struct {
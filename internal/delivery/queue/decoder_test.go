@@ -1,8 +1,12 @@
 package queue
 
 import (
+	"context"
 	"testing"
 
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+
 	pb "kafka-consumer/proto"
 )
 
@@ -19,7 +23,7 @@ func TestDecoder_DecodeJSON(t *testing.T) {
 		}
 	}`)
 
-	result, err := decoder.DecodeCommand(jsonData)
+	result, err := decoder.DecodeCommand(context.Background(), jsonData)
 	if err != nil {
 		t.Fatalf("Failed to decode JSON: %v", err)
 	}
@@ -61,7 +65,7 @@ func TestDecoder_DecodeJSON_AllCommands(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			jsonData := []byte(`{"id": "test", "command": "` + tc.command + `", "image_url": "https://example.com/img.jpg"}`)
-			result, err := decoder.DecodeCommand(jsonData)
+			result, err := decoder.DecodeCommand(context.Background(), jsonData)
 			if err != nil {
 				t.Fatalf("Failed to decode: %v", err)
 			}
@@ -77,8 +81,59 @@ func TestDecoder_DecodeJSON_AllCommands(t *testing.T) {
 func TestDecoder_DecodeInvalidJSON(t *testing.T) {
 	decoder := NewDecoder(FormatJSON)
 
-	_, err := decoder.DecodeCommand([]byte("{ invalid json }"))
+	_, err := decoder.DecodeCommand(context.Background(), []byte("{ invalid json }"))
 	if err == nil {
 		t.Error("Expected error for invalid JSON")
 	}
 }
+
+// TestDecodeDynamicProtobuf_RoundTrips builds a FileDescriptorProto the same
+// way the producer's encodeSchemaRegistry does, then decodes a real
+// *pb.ImageCommand payload against it to make sure decodeDynamicProtobuf
+// resolves a proper message descriptor instead of panicking on a nil one.
+func TestDecodeDynamicProtobuf_RoundTrips(t *testing.T) {
+	cmd := &pb.ImageCommand{
+		Id:       "schema-registry-1",
+		ImageUrl: "https://example.com/image.jpg",
+		Command:  pb.CommandType_COMMAND_TYPE_RESIZE,
+		Parameters: &pb.ImageCommand_Resize{
+			Resize: &pb.ResizeParameters{Width: 100, Height: 200},
+		},
+	}
+	payload, err := proto.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("proto.Marshal: %v", err)
+	}
+
+	fileDesc := protodesc.ToFileDescriptorProto(cmd.ProtoReflect().Descriptor().ParentFile())
+	rawDescriptor, err := proto.Marshal(fileDesc)
+	if err != nil {
+		t.Fatalf("failed to marshal file descriptor: %v", err)
+	}
+
+	got, err := decodeDynamicProtobuf(string(rawDescriptor), payload)
+	if err != nil {
+		t.Fatalf("decodeDynamicProtobuf: %v", err)
+	}
+
+	if got.Id != cmd.Id {
+		t.Errorf("expected Id %q, got %q", cmd.Id, got.Id)
+	}
+	if got.ImageUrl != cmd.ImageUrl {
+		t.Errorf("expected ImageUrl %q, got %q", cmd.ImageUrl, got.ImageUrl)
+	}
+	if got.Command != cmd.Command {
+		t.Errorf("expected Command %v, got %v", cmd.Command, got.Command)
+	}
+	if got.GetResize().GetWidth() != 100 || got.GetResize().GetHeight() != 200 {
+		t.Errorf("expected resize params 100x200, got %dx%d", got.GetResize().GetWidth(), got.GetResize().GetHeight())
+	}
+}
+
+// TestDecodeDynamicProtobuf_EmptyDescriptor ensures a schema with no message
+// types is rejected before reaching protodesc/dynamicpb.
+func TestDecodeDynamicProtobuf_EmptyDescriptor(t *testing.T) {
+	if _, err := decodeDynamicProtobuf("", []byte{}); err == nil {
+		t.Error("expected error for an empty/unparseable descriptor")
+	}
+}
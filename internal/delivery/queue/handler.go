@@ -2,28 +2,63 @@ package queue
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 
+	"kafka-consumer/internal/jobs"
+	kafkainternal "kafka-consumer/internal/kafka"
 	"kafka-consumer/internal/pkg/logger"
+	"kafka-consumer/internal/pkg/metrics"
 	"kafka-consumer/internal/processor"
+	pb "kafka-consumer/proto"
 )
 
 // MessageDecoder decodes raw message bytes into commands
 type MessageDecoder interface {
-	DecodeCommand(data []byte) (interface{}, error)
+	DecodeCommand(ctx context.Context, data []byte) (interface{}, error)
+}
+
+// Reader is the subset of *kafka.Reader's methods Handler depends on,
+// extracted so a Handler can run against a fake queue (see queue/tester)
+// instead of a live broker.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Config() kafka.ReaderConfig
+	Close() error
 }
 
 // Handler handles Kafka messages
 type Handler struct {
-	reader    *kafka.Reader
-	processor processor.Processor
-	decoder   MessageDecoder
+	reader      Reader
+	processor   processor.Processor
+	decoder     MessageDecoder
+	codec       MessageCodec
+	retryCfg    RetryConfig
+	dlqProducer *kafkainternal.DLQProducer
+	jobTracker  *jobs.Tracker
+
+	backoff SimpleBackoff
+
+	stateMu     sync.Mutex
+	state       State
+	subscribers []chan State
 }
 
-// NewHandler creates a new Kafka message handler
-func NewHandler(brokers []string, topic, groupID string, proc processor.Processor, decoder MessageDecoder) *Handler {
+// NewHandler creates a new Kafka message handler. dlqProducer may be nil, in
+// which case undecodable/failed messages are logged and committed as before.
+// dialer may be nil to use kafka-go's default dialer; pass one configured
+// with a SASL mechanism (e.g. via internal/pkg/auth) to authenticate.
+// jobTracker may be nil to skip job-state tracking entirely. codec may be
+// nil to leave message values as-is; pass GzipSnappyCodec{} to accept
+// producers that compress inline-image payloads before publishing.
+func NewHandler(brokers []string, topic, groupID string, proc processor.Processor, decoder MessageDecoder, retryCfg RetryConfig, dlqProducer *kafkainternal.DLQProducer, dialer *kafka.Dialer, jobTracker *jobs.Tracker, codec MessageCodec) *Handler {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        brokers,
 		Topic:          topic,
@@ -32,33 +67,129 @@ func NewHandler(brokers []string, topic, groupID string, proc processor.Processo
 		MaxBytes:       10e6, // 10MB
 		MaxWait:        1 * time.Second,
 		CommitInterval: time.Second,
+		Dialer:         dialer,
 	})
 
+	return NewHandlerWithReader(reader, proc, decoder, retryCfg, dlqProducer, jobTracker, codec)
+}
+
+// NewHandlerWithReader builds a Handler around an already-constructed
+// Reader instead of dialing a real Kafka broker, so a fake queue (see
+// queue/tester) can stand in for Kafka end-to-end in tests.
+func NewHandlerWithReader(reader Reader, proc processor.Processor, decoder MessageDecoder, retryCfg RetryConfig, dlqProducer *kafkainternal.DLQProducer, jobTracker *jobs.Tracker, codec MessageCodec) *Handler {
 	return &Handler{
-		reader:    reader,
-		processor: proc,
-		decoder:   decoder,
+		reader:      reader,
+		processor:   proc,
+		decoder:     decoder,
+		codec:       codec,
+		retryCfg:    retryCfg,
+		dlqProducer: dlqProducer,
+		jobTracker:  jobTracker,
+		backoff:     SimpleBackoff{Initial: 500 * time.Millisecond, Factor: 2, Max: 30 * time.Second},
 	}
 }
 
+// State returns a channel that receives every state transition the consume
+// loop makes from here on. The channel is buffered by one; a subscriber
+// that falls behind simply misses intermediate states rather than blocking
+// the consume loop.
+func (h *Handler) State() <-chan State {
+	ch := make(chan State, 1)
+	h.stateMu.Lock()
+	h.subscribers = append(h.subscribers, ch)
+	h.stateMu.Unlock()
+	return ch
+}
+
+// CurrentState returns the consume loop's most recently published state.
+func (h *Handler) CurrentState() State {
+	h.stateMu.Lock()
+	defer h.stateMu.Unlock()
+	return h.state
+}
+
+// ReadyState reports the consume loop's current state name and whether it
+// should be considered ready to serve traffic, for wiring into an HTTP
+// readiness check.
+func (h *Handler) ReadyState() (string, bool) {
+	state := h.CurrentState()
+	return state.String(), state == StateRunning || state == StateRebalancing
+}
+
+// setState records a new state, updates the kafka_consumer_state gauge, and
+// notifies subscribers without blocking on a slow or absent receiver.
+//
+// The gauge's partition label is the reader's static Config().Partition,
+// which is only meaningful for a manually-assigned (non-group) reader; a
+// consumer-group reader like this one always reports 0 even though it may
+// be handling several partitions, since kafka-go doesn't expose which ones
+// without tracking every FetchMessage result.
+func (h *Handler) setState(s State) {
+	h.stateMu.Lock()
+	h.state = s
+	subs := h.subscribers
+	h.stateMu.Unlock()
+
+	metrics.SetConsumerState(strconv.Itoa(h.reader.Config().Partition), float64(s))
+
+	for _, ch := range subs {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+// isRebalanceError is a best-effort heuristic for detecting consumer-group
+// rebalance activity. kafka-go doesn't surface rebalances as a distinct,
+// reliably-typed error, so this matches on the text its FetchMessage is
+// known to return during one rather than guaranteeing precise detection.
+func isRebalanceError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "rebalance")
+}
+
+// stateForError classifies a processNextMessage failure into the state the
+// consume loop should report while backing off and retrying.
+func stateForError(err error) State {
+	if isRebalanceError(err) {
+		return StateRebalancing
+	}
+	return StateDisconnected
+}
+
 // Start begins consuming messages from Kafka
 // This method blocks until context is cancelled or an error occurs
 func (h *Handler) Start(ctx context.Context) error {
 	logger.Info("Starting Kafka consumer", "topic", h.reader.Config().Topic)
+	h.setState(StateConnecting)
 
 	for {
 		select {
 		case <-ctx.Done():
 			logger.Info("Received stop signal, shutting down consumer")
+			h.setState(StateStopped)
 			return h.Close()
 		default:
 			if err := h.processNextMessage(ctx); err != nil {
 				if ctx.Err() != nil {
+					h.setState(StateStopped)
 					return nil // Context cancelled, graceful shutdown
 				}
-				// Log and continue on non-fatal errors
-				logger.Error("Error processing message", "error", err)
+
+				h.setState(stateForError(err))
+				delay := h.backoff.Next()
+				logger.Error("Error processing message, backing off", "error", err, "backoff", delay)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					h.setState(StateStopped)
+					return nil
+				}
+				continue
 			}
+
+			h.backoff.Reset()
+			h.setState(StateRunning)
 		}
 	}
 }
@@ -70,31 +201,151 @@ func (h *Handler) processNextMessage(ctx context.Context) error {
 		return err
 	}
 
-	log := logger.With(
+	log := logger.FromContext(ctx).With(
+		"topic", h.reader.Config().Topic,
 		"partition", msg.Partition,
 		"offset", msg.Offset,
 		"key", string(msg.Key),
+		"trace_id", traceIDOf(msg),
 	)
+	ctx = logger.WithContext(ctx, log)
 
 	log.Debug("Received message")
 
+	// firstSeen marks when this handler first took possession of the
+	// message, so the DLQ envelope can report how long it sat in the retry
+	// loop before being given up on.
+	firstSeen := time.Now()
+
+	value, err := h.decompress(msg)
+	if err != nil {
+		log.Error("Message decompression error", "error", err)
+		h.sendToDLQ(ctx, msg, "decompress_error", err, 0, firstSeen)
+		// The message has already been handed to the DLQ, so this is a
+		// resolved outcome, not a connectivity problem: commit and return nil
+		// so Start's backoff/state tracking doesn't mistake it for one.
+		if commitErr := h.reader.CommitMessages(ctx, msg); commitErr != nil {
+			log.Error("Commit error after decompression failure", "error", commitErr)
+			return commitErr
+		}
+		return nil
+	}
+
 	// Decode message
-	cmd, err := h.decoder.DecodeCommand(msg.Value)
+	cmd, err := h.decoder.DecodeCommand(ctx, value)
 	if err != nil {
 		log.Error("Message decode error", "error", err)
-		// Commit message even on error to avoid infinite retry
-		return h.reader.CommitMessages(ctx, msg)
+		h.sendToDLQ(ctx, msg, "decode_error", err, 0, firstSeen)
+		// Same as above: already resolved via the DLQ, so commit and return
+		// nil rather than flipping consumer state to disconnected.
+		if commitErr := h.reader.CommitMessages(ctx, msg); commitErr != nil {
+			log.Error("Commit error after decode failure", "error", commitErr)
+			return commitErr
+		}
+		return nil
+	}
+
+	jobID := messageIDOf(cmd)
+	log = log.With("message_id", jobID)
+	ctx = logger.WithContext(ctx, log)
+
+	h.jobTracker.MarkRunning(jobID)
+
+	// Process the command with retry, falling back to the DLQ once exhausted.
+	// The processor handles its own success/error metrics per attempt.
+	maxAttempts := h.retryCfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var procErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		procErr = h.processor.ProcessAny(ctx, cmd)
+		if procErr == nil {
+			break
+		}
+
+		log.Error("Command processing error", "error", procErr, "attempt", attempt, "max_attempts", maxAttempts)
+		if attempt == maxAttempts {
+			break
+		}
+
+		metrics.RecordRetry(commandTypeOf(cmd))
+		select {
+		case <-time.After(h.retryCfg.backoffDuration(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
-	// Process the command - the processor handles its own metrics
-	if err := h.processor.ProcessAny(ctx, cmd); err != nil {
-		log.Error("Command processing error", "error", err)
+	if procErr != nil {
+		h.sendToDLQ(ctx, msg, "processing_failed", procErr, maxAttempts, firstSeen)
+		h.jobTracker.MarkFailed(jobID, procErr.Error())
+	} else {
+		h.jobTracker.MarkSucceeded(jobID, "")
 	}
 
 	// Commit the message
 	return h.reader.CommitMessages(ctx, msg)
 }
 
+// decompress returns msg's value run through h.codec, if one is configured;
+// otherwise the value is returned unchanged.
+func (h *Handler) decompress(msg kafka.Message) ([]byte, error) {
+	if h.codec == nil {
+		return msg.Value, nil
+	}
+	return h.codec.Decompress(msg.Headers, msg.Value)
+}
+
+// sendToDLQ republishes a failed message to the configured dead-letter
+// topic, if one is set. firstSeen is when this handler first took
+// possession of the message, stamped on the envelope as x-first-seen.
+func (h *Handler) sendToDLQ(ctx context.Context, msg kafka.Message, reason string, cause error, attempts int, firstSeen time.Time) {
+	if h.dlqProducer == nil {
+		return
+	}
+	if err := h.dlqProducer.Send(ctx, h.reader.Config().Topic, msg.Partition, msg.Offset, msg.Key, msg.Value, cause, attempts, firstSeen); err != nil {
+		logger.FromContext(ctx).Error("Failed to publish message to DLQ", "error", err, "reason", reason)
+		return
+	}
+	metrics.RecordDLQSend(reason)
+}
+
+// commandTypeOf extracts a label-friendly command type from a decoded command,
+// falling back to "unknown" for types the decoder doesn't produce.
+func commandTypeOf(cmd interface{}) string {
+	if pbCmd, ok := cmd.(*pb.ImageCommand); ok {
+		return pbCmd.Command.String()
+	}
+	return "unknown"
+}
+
+// messageIDOf extracts the command ID for log correlation, falling back to
+// "unknown" for types the decoder doesn't produce.
+func messageIDOf(cmd interface{}) string {
+	if pbCmd, ok := cmd.(*pb.ImageCommand); ok {
+		return pbCmd.Id
+	}
+	return "unknown"
+}
+
+// traceIDOf looks for an incoming trace_id Kafka header (e.g. propagated by
+// an upstream producer), generating a fresh one if the message carries none,
+// so every log line for this message can still be correlated by trace_id.
+func traceIDOf(msg kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == "trace_id" && len(h.Value) > 0 {
+			return string(h.Value)
+		}
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
 // Close closes the Kafka consumer
 func (h *Handler) Close() error {
 	logger.Info("Closing Kafka connection")
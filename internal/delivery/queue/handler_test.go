@@ -0,0 +1,249 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"kafka-consumer/internal/processor"
+	pb "kafka-consumer/proto"
+)
+
+// fakeReader is a minimal Reader for tests: it returns messages from a
+// fixed queue, then fetchErr (or context.Canceled once the context is done)
+// once the queue is drained.
+type fakeReader struct {
+	messages  []kafka.Message
+	fetchErr  error
+	committed []kafka.Message
+	cfg       kafka.ReaderConfig
+}
+
+func (f *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(f.messages) > 0 {
+		msg := f.messages[0]
+		f.messages = f.messages[1:]
+		return msg, nil
+	}
+	if f.fetchErr != nil {
+		return kafka.Message{}, f.fetchErr
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (f *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.committed = append(f.committed, msgs...)
+	return nil
+}
+
+func (f *fakeReader) Config() kafka.ReaderConfig { return f.cfg }
+
+func (f *fakeReader) Close() error { return nil }
+
+// fakeDecoder lets tests control whether DecodeCommand succeeds.
+type fakeDecoder struct {
+	decodeErr error
+}
+
+func (d *fakeDecoder) DecodeCommand(ctx context.Context, data []byte) (interface{}, error) {
+	if d.decodeErr != nil {
+		return nil, d.decodeErr
+	}
+	return &pb.ImageCommand{Id: "test", ImageUrl: "https://example.com/img.jpg"}, nil
+}
+
+func newTestHandler(reader Reader, decoder MessageDecoder) *Handler {
+	return &Handler{
+		reader:    reader,
+		processor: processor.NewStubProcessor(),
+		decoder:   decoder,
+		retryCfg:  RetryConfig{MaxAttempts: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		backoff:   SimpleBackoff{Initial: time.Millisecond, Factor: 2, Max: 10 * time.Millisecond},
+	}
+}
+
+// fakeProcessor fails its first failTimes calls, then succeeds, so tests can
+// drive processNextMessage's retry loop through a transient failure.
+type fakeProcessor struct {
+	failTimes int
+	calls     int
+}
+
+func (p *fakeProcessor) Process(ctx context.Context, cmd *pb.ImageCommand) error { return nil }
+
+func (p *fakeProcessor) ProcessAny(ctx context.Context, cmd interface{}) error {
+	p.calls++
+	if p.calls <= p.failTimes {
+		return errors.New("transient processing failure")
+	}
+	return nil
+}
+
+func newTestHandlerWithRetry(reader Reader, decoder MessageDecoder, proc processor.Processor, retryCfg RetryConfig) *Handler {
+	return &Handler{
+		reader:    reader,
+		processor: proc,
+		decoder:   decoder,
+		retryCfg:  retryCfg,
+		backoff:   SimpleBackoff{Initial: time.Millisecond, Factor: 2, Max: 10 * time.Millisecond},
+	}
+}
+
+func TestHandler_ProcessNextMessage_DecodeErrorCommitsAndReturnsNil(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Value: []byte("bad")}}}
+	decoder := &fakeDecoder{decodeErr: errors.New("boom")}
+	h := newTestHandler(reader, decoder)
+
+	err := h.processNextMessage(context.Background())
+	if err != nil {
+		t.Fatalf("expected a decode error to be resolved via the DLQ and not propagate as a connectivity failure, got %v", err)
+	}
+	if len(reader.committed) != 1 {
+		t.Errorf("expected the undecodable message to still be committed, got %d commits", len(reader.committed))
+	}
+}
+
+func TestHandler_ProcessNextMessage_Success(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Value: []byte("ok")}}}
+	decoder := &fakeDecoder{}
+	h := newTestHandler(reader, decoder)
+
+	if err := h.processNextMessage(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(reader.committed) != 1 {
+		t.Errorf("expected the message to be committed, got %d commits", len(reader.committed))
+	}
+}
+
+func TestHandler_ProcessNextMessage_RetriesThenSucceeds(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Value: []byte("ok")}}}
+	proc := &fakeProcessor{failTimes: 2}
+	retryCfg := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	h := newTestHandlerWithRetry(reader, &fakeDecoder{}, proc, retryCfg)
+
+	if err := h.processNextMessage(context.Background()); err != nil {
+		t.Fatalf("expected the message to succeed after retries, got %v", err)
+	}
+	if proc.calls != 3 {
+		t.Errorf("expected 3 processing attempts (2 failures + 1 success), got %d", proc.calls)
+	}
+	if len(reader.committed) != 1 {
+		t.Errorf("expected the message to be committed once it succeeds, got %d commits", len(reader.committed))
+	}
+}
+
+func TestHandler_ProcessNextMessage_ExhaustsRetriesAndCommits(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Value: []byte("bad")}}}
+	proc := &fakeProcessor{failTimes: 10}
+	retryCfg := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	h := newTestHandlerWithRetry(reader, &fakeDecoder{}, proc, retryCfg)
+
+	if err := h.processNextMessage(context.Background()); err != nil {
+		t.Fatalf("expected processNextMessage to return nil once retries are exhausted and the message is DLQ'd/committed, got %v", err)
+	}
+	if proc.calls != retryCfg.MaxAttempts {
+		t.Errorf("expected exactly %d processing attempts, got %d", retryCfg.MaxAttempts, proc.calls)
+	}
+	if len(reader.committed) != 1 {
+		t.Errorf("expected the exhausted message to still be committed (no dlqProducer configured), got %d commits", len(reader.committed))
+	}
+}
+
+func TestHandler_Start_ReturnsNilAndStopsAfterFetchErrors(t *testing.T) {
+	reader := &fakeReader{fetchErr: errors.New("connection refused")}
+	h := newTestHandler(reader, &fakeDecoder{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := h.Start(ctx); err != nil {
+		t.Fatalf("expected Start to return nil on context cancellation, got %v", err)
+	}
+
+	if got := h.CurrentState(); got != StateStopped {
+		t.Errorf("expected final state %v, got %v", StateStopped, got)
+	}
+}
+
+func TestHandler_Start_ReportsRunningAfterSuccess(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Value: []byte("ok")}}}
+	h := newTestHandler(reader, &fakeDecoder{})
+	states := h.State()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	go h.Start(ctx)
+
+	sawRunning := false
+	for !sawRunning {
+		select {
+		case s := <-states:
+			if s == StateRunning {
+				sawRunning = true
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("timed out waiting for StateRunning")
+		}
+	}
+}
+
+func TestStateForError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected State
+	}{
+		{"generic error", errors.New("connection refused"), StateDisconnected},
+		{"rebalance error", errors.New("group is rebalancing"), StateRebalancing},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := stateForError(tc.err); got != tc.expected {
+				t.Errorf("stateForError(%v) = %v, want %v", tc.err, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSimpleBackoff_NextDoublesUpToMaxAndResets(t *testing.T) {
+	b := SimpleBackoff{Initial: 10 * time.Millisecond, Factor: 2, Max: 35 * time.Millisecond}
+
+	got := []time.Duration{b.Next(), b.Next(), b.Next(), b.Next()}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 35 * time.Millisecond, 35 * time.Millisecond}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Next() call %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	b.Reset()
+	if got := b.Next(); got != 10*time.Millisecond {
+		t.Errorf("Next() after Reset() = %v, want %v", got, 10*time.Millisecond)
+	}
+}
+
+func TestState_String(t *testing.T) {
+	testCases := []struct {
+		state State
+		want  string
+	}{
+		{StateDisconnected, "disconnected"},
+		{StateConnecting, "connecting"},
+		{StateRunning, "running"},
+		{StateRebalancing, "rebalancing"},
+		{StateStopped, "stopped"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.state.String(); got != tc.want {
+			t.Errorf("State(%d).String() = %q, want %q", tc.state, got, tc.want)
+		}
+	}
+}
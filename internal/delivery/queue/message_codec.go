@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/segmentio/kafka-go"
+)
+
+// compressionHeader is a Kafka header a producer may set to declare how it
+// compressed this message's value, independent of Kafka's own record-batch
+// compression (which kafka-go already decompresses transparently on
+// fetch). This lets a producer ship a large inline image well under
+// Reader.MaxBytes on the wire while Handler still sees the decompressed
+// bytes before decoding.
+const compressionHeader = "compression"
+
+// gzipMagic and snappyFrameMagic let Decompress recognize a compressed
+// value even when a producer didn't set compressionHeader.
+var (
+	gzipMagic        = []byte{0x1f, 0x8b}
+	snappyFrameMagic = []byte("\xff\x06\x00\x00sNaPpY")
+)
+
+// MessageCodec transparently decompresses a message's value before it's
+// handed to a MessageDecoder.
+type MessageCodec interface {
+	Decompress(headers []kafka.Header, value []byte) ([]byte, error)
+}
+
+// GzipSnappyCodec decompresses gzip- and snappy-framed message values,
+// detected via the "compression" Kafka header or, absent that, a
+// magic-byte sniff. Anything else passes through unchanged.
+type GzipSnappyCodec struct{}
+
+// Decompress implements MessageCodec.
+func (GzipSnappyCodec) Decompress(headers []kafka.Header, value []byte) ([]byte, error) {
+	switch compressionOf(headers, value) {
+	case "gzip":
+		return decodeGzip(value)
+	case "snappy":
+		return decodeSnappy(value)
+	default:
+		return value, nil
+	}
+}
+
+// compressionOf reports the compression a message's value was encoded
+// with, preferring the explicit header and falling back to a magic-byte
+// sniff of the value itself.
+func compressionOf(headers []kafka.Header, value []byte) string {
+	for _, h := range headers {
+		if h.Key == compressionHeader {
+			return string(h.Value)
+		}
+	}
+	if bytes.HasPrefix(value, gzipMagic) {
+		return "gzip"
+	}
+	if bytes.HasPrefix(value, snappyFrameMagic) {
+		return "snappy"
+	}
+	return ""
+}
+
+func decodeGzip(value []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gzip reader: %w", err)
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip value: %w", err)
+	}
+	return out, nil
+}
+
+func decodeSnappy(value []byte) ([]byte, error) {
+	out, err := io.ReadAll(snappy.NewReader(bytes.NewReader(value)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snappy value: %w", err)
+	}
+	return out, nil
+}
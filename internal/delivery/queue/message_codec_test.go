@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/segmentio/kafka-go"
+)
+
+func gzipCompress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func snappyCompress(data []byte) []byte {
+	var buf bytes.Buffer
+	w := snappy.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestGzipSnappyCodec_Decompress_GzipViaHeader(t *testing.T) {
+	original := []byte("hello gzip world")
+	compressed := gzipCompress(t, original)
+
+	got, err := GzipSnappyCodec{}.Decompress([]kafka.Header{{Key: compressionHeader, Value: []byte("gzip")}}, compressed)
+	if err != nil {
+		t.Fatalf("Decompress: %v", err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Errorf("expected %q, got %q", original, got)
+	}
+}
+
+func TestGzipSnappyCodec_Decompress_SnappyViaMagicSniff(t *testing.T) {
+	original := bytes.Repeat([]byte("inline-image-bytes"), 64)
+	compressed := snappyCompress(original)
+
+	got, err := GzipSnappyCodec{}.Decompress(nil, compressed)
+	if err != nil {
+		t.Fatalf("Decompress: %v", err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Errorf("round-tripped value doesn't match original (%d vs %d bytes)", len(got), len(original))
+	}
+}
+
+func TestGzipSnappyCodec_Decompress_Uncompressed(t *testing.T) {
+	original := []byte("plain value")
+
+	got, err := GzipSnappyCodec{}.Decompress(nil, original)
+	if err != nil {
+		t.Fatalf("Decompress: %v", err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Errorf("expected uncompressed value to pass through unchanged, got %q", got)
+	}
+}
+
+func BenchmarkGzipSnappyCodec_Decompress_Snappy(b *testing.B) {
+	original := bytes.Repeat([]byte("inline-image-bytes"), 1024)
+	compressed := snappyCompress(original)
+	codec := GzipSnappyCodec{}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := codec.Decompress(nil, compressed); err != nil {
+			b.Fatalf("Decompress: %v", err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package queue
+
+// State represents the current connection/processing state of a Handler's
+// consume loop, published to subscribers via Handler.State().
+type State int
+
+const (
+	StateDisconnected State = iota
+	StateConnecting
+	StateRunning
+	StateRebalancing
+	StateStopped
+)
+
+// String returns a human-readable name for s, used in logs and in the
+// value reported by Handler.ReadyState.
+func (s State) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateRunning:
+		return "running"
+	case StateRebalancing:
+		return "rebalancing"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,168 @@
+package tester_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/proto"
+
+	"kafka-consumer/internal/delivery/queue"
+	"kafka-consumer/internal/delivery/queue/tester"
+	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/processor"
+	"kafka-consumer/internal/puller"
+	pb "kafka-consumer/proto"
+)
+
+// TestTester_ResizeCommand drives a RESIZE command through a Tester-backed
+// Handler running RealProcessor end to end, and asserts the resulting file
+// and Prometheus counter.
+func TestTester_ResizeCommand(t *testing.T) {
+	outputDir := t.TempDir()
+	sourcePath := writeTestPNG(t)
+
+	proc, err := processor.NewRealProcessor(outputDir, puller.New(nil, puller.NewFileProvider()), 0, nil)
+	if err != nil {
+		t.Fatalf("NewRealProcessor: %v", err)
+	}
+
+	tst := tester.New()
+	handler := queue.NewHandlerWithReader(tst.Reader("image-commands"), proc, tst, queue.DefaultRetryConfig(), nil, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.Start(ctx)
+
+	cmd := &pb.ImageCommand{
+		Id:       "resize-1",
+		ImageUrl: sourcePath,
+		Command:  pb.CommandType_COMMAND_TYPE_RESIZE,
+		Parameters: &pb.ImageCommand_Resize{
+			Resize: &pb.ResizeParameters{Width: 10, Height: 10},
+		},
+	}
+	data, err := proto.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("proto.Marshal: %v", err)
+	}
+
+	before := testutil.ToFloat64(metrics.MessagesProcessedTotal.WithLabelValues(cmd.Command.String(), "success"))
+
+	tst.Consume("image-commands", cmd.Id, data)
+
+	msg, ok := tst.Tracker().NextMessage()
+	if !ok {
+		t.Fatal("timed out waiting for the handler to commit the resize command")
+	}
+	if string(msg.Key) != cmd.Id {
+		t.Errorf("expected committed message key %q, got %q", cmd.Id, string(msg.Key))
+	}
+
+	outputPath := filepath.Join(outputDir, cmd.Id+"_processed.png")
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("expected processed output at %s: %v", outputPath, err)
+	}
+
+	after := testutil.ToFloat64(metrics.MessagesProcessedTotal.WithLabelValues(cmd.Command.String(), "success"))
+	if after != before+1 {
+		t.Errorf("expected kafka_consumer_messages_processed_total{status=success} to increase by 1, got delta %v", after-before)
+	}
+
+	if err := tst.Tracker().ExpectEmpty(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestTester_SnappyCompressedInlineImage drives a RESIZE command whose
+// image is embedded as InlineImage bytes rather than a URL, published as a
+// snappy-compressed message value, through a Handler configured with
+// queue.GzipSnappyCodec.
+func TestTester_SnappyCompressedInlineImage(t *testing.T) {
+	outputDir := t.TempDir()
+	sourcePath := writeTestPNG(t)
+	sourceBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	proc, err := processor.NewRealProcessor(outputDir, puller.New(nil, puller.NewFileProvider()), 0, nil)
+	if err != nil {
+		t.Fatalf("NewRealProcessor: %v", err)
+	}
+
+	tst := tester.New()
+	handler := queue.NewHandlerWithReader(tst.Reader("image-commands"), proc, tst, queue.DefaultRetryConfig(), nil, nil, queue.GzipSnappyCodec{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.Start(ctx)
+
+	cmd := &pb.ImageCommand{
+		Id:           "inline-resize-1",
+		InlineImage:  sourceBytes,
+		InlineFormat: "png",
+		Command:      pb.CommandType_COMMAND_TYPE_RESIZE,
+		Parameters: &pb.ImageCommand_Resize{
+			Resize: &pb.ResizeParameters{Width: 10, Height: 10},
+		},
+	}
+	data, err := proto.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("proto.Marshal: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	w := snappy.NewWriter(&compressed)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("snappy write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("snappy close: %v", err)
+	}
+
+	tst.Consume("image-commands", cmd.Id, compressed.Bytes())
+
+	msg, ok := tst.Tracker().NextMessage()
+	if !ok {
+		t.Fatal("timed out waiting for the handler to commit the inline resize command")
+	}
+	if string(msg.Key) != cmd.Id {
+		t.Errorf("expected committed message key %q, got %q", cmd.Id, string(msg.Key))
+	}
+
+	outputPath := filepath.Join(outputDir, cmd.Id+"_processed.png")
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("expected processed output at %s: %v", outputPath, err)
+	}
+}
+
+func writeTestPNG(t *testing.T) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "source.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create source image: %v", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("encode source image: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,81 @@
+package tester
+
+import (
+	"context"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader is an in-memory queue.Reader backed by a simple FIFO, fed by
+// Tester.Consume and drained by a Handler's FetchMessage/CommitMessages
+// calls.
+type fakeReader struct {
+	topic   string
+	tracker *MessageTracker
+
+	mu     sync.Mutex
+	queue  []kafka.Message
+	notify chan struct{}
+}
+
+func newFakeReader(topic string, tracker *MessageTracker) *fakeReader {
+	return &fakeReader{
+		topic:   topic,
+		tracker: tracker,
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+func (f *fakeReader) enqueue(msg kafka.Message) {
+	f.mu.Lock()
+	f.queue = append(f.queue, msg)
+	f.mu.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// FetchMessage implements queue.Reader, blocking until a message is
+// available or ctx is done.
+func (f *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		f.mu.Lock()
+		if len(f.queue) > 0 {
+			msg := f.queue[0]
+			f.queue = f.queue[1:]
+			f.mu.Unlock()
+			f.tracker.recordConsumed(msg)
+			return msg, nil
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-f.notify:
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		}
+	}
+}
+
+// CommitMessages implements queue.Reader by recording each message on the
+// MessageTracker instead of talking to a broker.
+func (f *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		f.tracker.recordCommitted(msg)
+	}
+	return nil
+}
+
+// Config implements queue.Reader with just enough of kafka.ReaderConfig for
+// Handler's logging and metrics labels.
+func (f *fakeReader) Config() kafka.ReaderConfig {
+	return kafka.ReaderConfig{Topic: f.topic}
+}
+
+// Close implements queue.Reader; the fake queue holds no real resources.
+func (f *fakeReader) Close() error {
+	return nil
+}
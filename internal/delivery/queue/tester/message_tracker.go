@@ -0,0 +1,63 @@
+package tester
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// nextMessageTimeout bounds how long NextMessage waits for a Handler,
+// running in its own goroutine, to reach a commit.
+const nextMessageTimeout = 2 * time.Second
+
+// expectEmptyTimeout is how long ExpectEmpty waits to confirm nothing more
+// arrives; shorter than nextMessageTimeout since it's confirming an
+// absence rather than waiting out expected work.
+const expectEmptyTimeout = 200 * time.Millisecond
+
+// MessageTracker records every message a Tester-backed Handler consumes and
+// commits, so tests can assert on the outcome without polling internal
+// state directly.
+type MessageTracker struct {
+	consumed  chan kafka.Message
+	committed chan kafka.Message
+}
+
+func newMessageTracker() *MessageTracker {
+	return &MessageTracker{
+		consumed:  make(chan kafka.Message, 256),
+		committed: make(chan kafka.Message, 256),
+	}
+}
+
+func (t *MessageTracker) recordConsumed(msg kafka.Message) {
+	t.consumed <- msg
+}
+
+func (t *MessageTracker) recordCommitted(msg kafka.Message) {
+	t.committed <- msg
+}
+
+// NextMessage blocks until the Handler commits its next message, up to
+// nextMessageTimeout, and reports whether one arrived in time.
+func (t *MessageTracker) NextMessage() (kafka.Message, bool) {
+	select {
+	case msg := <-t.committed:
+		return msg, true
+	case <-time.After(nextMessageTimeout):
+		return kafka.Message{}, false
+	}
+}
+
+// ExpectEmpty returns an error if the Handler commits another message
+// within expectEmptyTimeout, for asserting that a Consume call produced no
+// further processing.
+func (t *MessageTracker) ExpectEmpty() error {
+	select {
+	case msg := <-t.committed:
+		return fmt.Errorf("expected no more committed messages, got one for key %q", string(msg.Key))
+	case <-time.After(expectEmptyTimeout):
+		return nil
+	}
+}
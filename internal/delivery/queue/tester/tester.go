@@ -0,0 +1,70 @@
+// Package tester provides an in-process, in-memory stand-in for a real
+// Kafka broker, modeled on goka's tester package. It lets tests drive
+// queue.Handler end to end (decode, process, commit) via
+// queue.NewHandlerWithReader, without a running broker.
+package tester
+
+import (
+	"context"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+
+	"kafka-consumer/internal/delivery/queue"
+)
+
+// Tester is a fake Kafka transport: it hands out a queue.Reader per topic
+// and implements queue.MessageDecoder itself, decoding messages the same
+// way production does (protobuf over the wire).
+type Tester struct {
+	decoder *queue.Decoder
+	tracker *MessageTracker
+
+	mu     sync.Mutex
+	topics map[string]*fakeReader
+}
+
+// New creates an empty Tester.
+func New() *Tester {
+	return &Tester{
+		decoder: queue.NewDecoder(queue.FormatProtobuf),
+		tracker: newMessageTracker(),
+		topics:  make(map[string]*fakeReader),
+	}
+}
+
+// DecodeCommand implements queue.MessageDecoder.
+func (t *Tester) DecodeCommand(ctx context.Context, data []byte) (interface{}, error) {
+	return t.decoder.DecodeCommand(ctx, data)
+}
+
+// Reader returns the queue.Reader backing topic, creating it on first use.
+// Pass it to queue.NewHandlerWithReader to run a Handler against this Tester.
+func (t *Tester) Reader(topic string) queue.Reader {
+	return t.reader(topic)
+}
+
+func (t *Tester) reader(topic string) *fakeReader {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.topics[topic]
+	if !ok {
+		r = newFakeReader(topic, t.tracker)
+		t.topics[topic] = r
+	}
+	return r
+}
+
+// Consume enqueues a raw message as if it had just been fetched from topic,
+// for a Handler running against this Tester (typically in its own
+// goroutine) to pick up via FetchMessage.
+func (t *Tester) Consume(topic, key string, value []byte) {
+	t.reader(topic).enqueue(kafka.Message{Topic: topic, Key: []byte(key), Value: value})
+}
+
+// Tracker returns the MessageTracker recording every message consumed and
+// committed across all topics.
+func (t *Tester) Tracker() *MessageTracker {
+	return t.tracker
+}
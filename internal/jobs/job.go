@@ -0,0 +1,48 @@
+// Package jobs tracks the lifecycle of asynchronously-processed commands
+// submitted via the HTTP API, from the moment they're enqueued to Kafka
+// until the consumer finishes processing them, and notifies callers via
+// webhook when a job reaches a terminal state.
+package jobs
+
+import "time"
+
+// Status is a job's position in its lifecycle.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job records the state of one asynchronously-processed command.
+type Job struct {
+	ID          string
+	Status      Status
+	Command     string
+	CallbackURL string
+	Result      string
+	Error       string
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
+
+// Store persists job records. The in-memory implementation is the default;
+// a Redis- or Postgres-backed Store can be added later behind this same
+// interface without touching callers.
+type Store interface {
+	// Create records a newly-submitted job.
+	Create(job *Job) error
+	// Get returns the job with the given ID, if any.
+	Get(id string) (*Job, bool)
+	// List returns jobs matching status, or every job if status is "".
+	List(status Status) []*Job
+	// SetRunning marks a job as picked up for processing.
+	SetRunning(id string) error
+	// SetSucceeded marks a job as complete, recording its result.
+	SetSucceeded(id, result string) error
+	// SetFailed marks a job as failed, recording the error message.
+	SetFailed(id, errMsg string) error
+}
@@ -0,0 +1,85 @@
+package jobs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store, suitable for a single-replica
+// deployment or tests. State is lost on restart.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+// NewMemoryStore creates an empty in-memory job store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+// Create implements Store.
+func (s *MemoryStore) Create(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(id string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// List implements Store.
+func (s *MemoryStore) List(status Status) []*Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if status == "" || job.Status == status {
+			result = append(result, job)
+		}
+	}
+	return result
+}
+
+// SetRunning implements Store.
+func (s *MemoryStore) SetRunning(id string) error {
+	return s.update(id, func(j *Job) {
+		j.Status = StatusRunning
+		j.StartedAt = time.Now()
+	})
+}
+
+// SetSucceeded implements Store.
+func (s *MemoryStore) SetSucceeded(id, result string) error {
+	return s.update(id, func(j *Job) {
+		j.Status = StatusSucceeded
+		j.Result = result
+		j.FinishedAt = time.Now()
+	})
+}
+
+// SetFailed implements Store.
+func (s *MemoryStore) SetFailed(id, errMsg string) error {
+	return s.update(id, func(j *Job) {
+		j.Status = StatusFailed
+		j.Error = errMsg
+		j.FinishedAt = time.Now()
+	})
+}
+
+func (s *MemoryStore) update(id string, fn func(*Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	fn(job)
+	return nil
+}
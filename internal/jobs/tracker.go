@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"kafka-consumer/internal/pkg/logger"
+)
+
+// Tracker combines a Store with a Notifier, updating job state as a command
+// moves through the consumer and firing a webhook notification when it
+// reaches a terminal state. A nil *Tracker is valid and a no-op, so callers
+// that don't need job tracking can simply leave it unset.
+type Tracker struct {
+	store    Store
+	notifier Notifier
+}
+
+// NewTracker creates a Tracker backed by store, notifying via notifier on
+// terminal states. notifier may be nil to disable webhook delivery.
+func NewTracker(store Store, notifier Notifier) *Tracker {
+	return &Tracker{store: store, notifier: notifier}
+}
+
+// Create records a newly-submitted job. It is a no-op on a nil Tracker.
+func (t *Tracker) Create(job *Job) error {
+	if t == nil {
+		return nil
+	}
+	return t.store.Create(job)
+}
+
+// MarkRunning marks id as picked up for processing. It is a no-op on a nil
+// Tracker.
+func (t *Tracker) MarkRunning(id string) {
+	if t == nil {
+		return
+	}
+	if err := t.store.SetRunning(id); err != nil {
+		logger.Error("Failed to mark job running", "job_id", id, "error", err)
+	}
+}
+
+// MarkSucceeded marks id as complete and notifies its callback, if any.
+func (t *Tracker) MarkSucceeded(id, result string) {
+	if t == nil {
+		return
+	}
+	if err := t.store.SetSucceeded(id, result); err != nil {
+		logger.Error("Failed to mark job succeeded", "job_id", id, "error", err)
+		return
+	}
+	t.notify(id)
+}
+
+// MarkFailed marks id as failed and notifies its callback, if any.
+func (t *Tracker) MarkFailed(id, errMsg string) {
+	if t == nil {
+		return
+	}
+	if err := t.store.SetFailed(id, errMsg); err != nil {
+		logger.Error("Failed to mark job failed", "job_id", id, "error", err)
+		return
+	}
+	t.notify(id)
+}
+
+// Get returns the job with the given ID, if any. It is a no-op returning
+// (nil, false) on a nil Tracker.
+func (t *Tracker) Get(id string) (*Job, bool) {
+	if t == nil {
+		return nil, false
+	}
+	return t.store.Get(id)
+}
+
+// List returns jobs matching status, or every job if status is "". It
+// returns nil on a nil Tracker.
+func (t *Tracker) List(status Status) []*Job {
+	if t == nil {
+		return nil
+	}
+	return t.store.List(status)
+}
+
+// notify fires the webhook for a job in the background, using a fresh
+// context so a slow or failing callback never blocks the consume loop.
+func (t *Tracker) notify(id string) {
+	if t.notifier == nil {
+		return
+	}
+	job, ok := t.store.Get(id)
+	if !ok || job.CallbackURL == "" {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := t.notifier.Notify(ctx, job); err != nil {
+			logger.Error("Failed to deliver job webhook", "job_id", job.ID, "callback_url", job.CallbackURL, "error", err)
+		}
+	}()
+}
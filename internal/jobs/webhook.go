@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Notifier delivers a terminal job state to whatever external system asked
+// to be notified about it.
+type Notifier interface {
+	Notify(ctx context.Context, job *Job) error
+}
+
+// WebhookNotifier POSTs a signed JSON payload to a job's CallbackURL when it
+// reaches a terminal state, retrying any failed delivery (a request error or
+// a status of 300 or above) with backoff.
+type WebhookNotifier struct {
+	client      *http.Client
+	secret      string
+	maxAttempts int
+	baseBackoff time.Duration
+}
+
+// NewWebhookNotifier creates a WebhookNotifier that signs each payload with
+// HMAC-SHA256 using secret. An empty secret disables signing.
+func NewWebhookNotifier(secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		secret:      secret,
+		maxAttempts: 3,
+		baseBackoff: 500 * time.Millisecond,
+	}
+}
+
+type webhookPayload struct {
+	ID         string `json:"id"`
+	Status     Status `json:"status"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// Notify implements Notifier.
+func (n *WebhookNotifier) Notify(ctx context.Context, job *Job) error {
+	body, err := json.Marshal(webhookPayloadFrom(job))
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(n.backoffDuration(attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		lastErr = n.send(ctx, job.CallbackURL, body)
+		if lastErr == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("webhook delivery to %s failed after %d attempts: %w", job.CallbackURL, n.maxAttempts, lastErr)
+}
+
+func (n *WebhookNotifier) send(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		req.Header.Set("X-Signature", n.sign(body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(n.secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoffDuration returns an exponential backoff with +/-20% jitter for the
+// given attempt number (1-indexed).
+func (n *WebhookNotifier) backoffDuration(attempt int) time.Duration {
+	d := n.baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+	}
+
+	jitter := 0.8 + rand.Float64()*0.4 // 0.8x - 1.2x
+	return time.Duration(float64(d) * jitter)
+}
+
+func webhookPayloadFrom(job *Job) webhookPayload {
+	p := webhookPayload{
+		ID:     job.ID,
+		Status: job.Status,
+		Result: job.Result,
+		Error:  job.Error,
+	}
+	if !job.StartedAt.IsZero() {
+		p.StartedAt = job.StartedAt.Format(time.RFC3339)
+	}
+	if !job.FinishedAt.IsZero() {
+		p.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return p
+}
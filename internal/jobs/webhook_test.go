@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_Notify_SignsPayload(t *testing.T) {
+	var gotBody []byte
+	var gotSig string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Signature")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier("top-secret")
+	job := &Job{ID: "job-1", Status: StatusSucceeded, Result: "ok", CallbackURL: server.URL}
+
+	if err := n.Notify(context.Background(), job); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("top-secret"))
+	mac.Write(gotBody)
+	want := hex.EncodeToString(mac.Sum(nil))
+	if gotSig != want {
+		t.Errorf("X-Signature = %q, want %q", gotSig, want)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("failed to unmarshal webhook payload: %v", err)
+	}
+	if payload.ID != "job-1" || payload.Status != StatusSucceeded || payload.Result != "ok" {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestWebhookNotifier_Notify_NoSecretNoSignature(t *testing.T) {
+	var gotSig string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier("")
+	job := &Job{ID: "job-1", Status: StatusSucceeded, CallbackURL: server.URL}
+
+	if err := n.Notify(context.Background(), job); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if gotSig != "" {
+		t.Errorf("expected no X-Signature header without a secret, got %q", gotSig)
+	}
+}
+
+func TestWebhookNotifier_Notify_RetriesThenSucceeds(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier("secret")
+	n.baseBackoff = time.Millisecond
+	job := &Job{ID: "job-1", Status: StatusFailed, CallbackURL: server.URL}
+
+	if err := n.Notify(context.Background(), job); err != nil {
+		t.Fatalf("expected delivery to succeed after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts (2 failures + 1 success), got %d", attempts)
+	}
+}
+
+func TestWebhookNotifier_Notify_ExhaustsRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier("secret")
+	n.baseBackoff = time.Millisecond
+	job := &Job{ID: "job-1", Status: StatusFailed, CallbackURL: server.URL}
+
+	if err := n.Notify(context.Background(), job); err == nil {
+		t.Fatal("expected an error once all attempts are exhausted")
+	}
+	if int(attempts) != n.maxAttempts {
+		t.Errorf("expected %d attempts, got %d", n.maxAttempts, attempts)
+	}
+}
+
+func TestWebhookNotifier_sign_IsDeterministic(t *testing.T) {
+	n := NewWebhookNotifier("shared-secret")
+	body := []byte(`{"id":"job-1"}`)
+
+	if n.sign(body) != n.sign(body) {
+		t.Error("sign should be deterministic for the same body and secret")
+	}
+
+	other := NewWebhookNotifier("different-secret")
+	if n.sign(body) == other.sign(body) {
+		t.Error("sign should differ across secrets")
+	}
+}
@@ -0,0 +1,139 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+
+	pb "kafka-consumer/proto"
+)
+
+// MessageFormat identifies the wire encoding of a message body, negotiated
+// via the "content-type" Kafka header.
+type MessageFormat string
+
+const (
+	FormatProtobuf MessageFormat = "application/x-protobuf"
+	FormatJSON     MessageFormat = "application/json"
+)
+
+// contentTypeHeader is the Kafka header carrying the negotiated MessageFormat.
+const contentTypeHeader = "content-type"
+
+// Codec encodes and decodes an *pb.ImageCommand to/from a message body.
+type Codec interface {
+	ContentType() MessageFormat
+	Encode(cmd *pb.ImageCommand) ([]byte, error)
+	Decode(data []byte) (*pb.ImageCommand, error)
+}
+
+// codecForFormat resolves the Codec for a given MessageFormat, defaulting to
+// protobuf for anything unrecognized.
+func codecForFormat(format MessageFormat) Codec {
+	if format == FormatJSON {
+		return jsonCodec{}
+	}
+	return protobufCodec{}
+}
+
+// protobufCodec encodes commands as raw protobuf.
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() MessageFormat { return FormatProtobuf }
+
+func (protobufCodec) Encode(cmd *pb.ImageCommand) ([]byte, error) {
+	return proto.Marshal(cmd)
+}
+
+func (protobufCodec) Decode(data []byte) (*pb.ImageCommand, error) {
+	cmd := &pb.ImageCommand{}
+	if err := proto.Unmarshal(data, cmd); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal protobuf command: %w", err)
+	}
+	return cmd, nil
+}
+
+// jsonCodec encodes commands as JSON, for producers/consumers that haven't
+// migrated to protobuf yet. Only the fields the legacy JSON wire format
+// carries are round-tripped; typed parameters are not supported.
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() MessageFormat { return FormatJSON }
+
+type jsonCommand struct {
+	ID       string `json:"id"`
+	Command  string `json:"command"`
+	ImageURL string `json:"image_url"`
+}
+
+func (jsonCodec) Encode(cmd *pb.ImageCommand) ([]byte, error) {
+	return json.Marshal(jsonCommand{
+		ID:       cmd.Id,
+		Command:  commandTypeToString(cmd.Command),
+		ImageURL: cmd.ImageUrl,
+	})
+}
+
+func (jsonCodec) Decode(data []byte) (*pb.ImageCommand, error) {
+	var jc jsonCommand
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON command: %w", err)
+	}
+	return &pb.ImageCommand{
+		Id:       jc.ID,
+		Command:  commandTypeFromString(jc.Command),
+		ImageUrl: jc.ImageURL,
+	}, nil
+}
+
+func commandTypeToString(command pb.CommandType) string {
+	switch command {
+	case pb.CommandType_COMMAND_TYPE_RESIZE:
+		return "resize"
+	case pb.CommandType_COMMAND_TYPE_FILTER:
+		return "filter"
+	case pb.CommandType_COMMAND_TYPE_TRANSFORM:
+		return "transform"
+	case pb.CommandType_COMMAND_TYPE_ANALYZE:
+		return "analyze"
+	case pb.CommandType_COMMAND_TYPE_CROP:
+		return "crop"
+	case pb.CommandType_COMMAND_TYPE_REMOVE_BACKGROUND:
+		return "remove_background"
+	default:
+		return ""
+	}
+}
+
+func commandTypeFromString(command string) pb.CommandType {
+	switch command {
+	case "resize":
+		return pb.CommandType_COMMAND_TYPE_RESIZE
+	case "filter":
+		return pb.CommandType_COMMAND_TYPE_FILTER
+	case "transform":
+		return pb.CommandType_COMMAND_TYPE_TRANSFORM
+	case "analyze":
+		return pb.CommandType_COMMAND_TYPE_ANALYZE
+	case "crop":
+		return pb.CommandType_COMMAND_TYPE_CROP
+	case "remove_background":
+		return pb.CommandType_COMMAND_TYPE_REMOVE_BACKGROUND
+	default:
+		return pb.CommandType_COMMAND_TYPE_UNSPECIFIED
+	}
+}
+
+// contentTypeOf looks up the negotiated content-type header on a message,
+// defaulting to protobuf when the header is absent. An unrecognized value is
+// returned as-is; codecForFormat maps it to protobuf.
+func contentTypeOf(headers []kafka.Header) MessageFormat {
+	for _, h := range headers {
+		if h.Key == contentTypeHeader {
+			return MessageFormat(h.Value)
+		}
+	}
+	return FormatProtobuf
+}
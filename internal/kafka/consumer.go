@@ -2,13 +2,19 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
 
-	"kafka-consumer/internal/models"
+	"kafka-consumer/internal/pkg/customerrors"
+	"kafka-consumer/internal/pkg/logger"
+	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/pkg/tracing"
 	"kafka-consumer/internal/processor"
 )
 
@@ -16,11 +22,23 @@ import (
 type Consumer struct {
 	reader             *kafka.Reader
 	processor          processor.Processor
+	dlqProducer        *DLQProducer
+	retryCfg           RetryConfig
 	onMessageProcessed func()
+	onDLQ              func()
 }
 
-// NewConsumer creates a new Kafka consumer
-func NewConsumer(brokers []string, topic, groupID string, proc processor.Processor) *Consumer {
+// NewConsumer creates a new Kafka consumer. dlqProducer may be nil, in which
+// case messages that exhaust retries are logged and committed rather than
+// republished. security may be the zero value for a plaintext,
+// unauthenticated connection; it returns an error if the configured SASL
+// mechanism or TLS material is invalid, so callers can fail fast at startup.
+func NewConsumer(brokers []string, topic, groupID string, proc processor.Processor, dlqProducer *DLQProducer, retryCfg RetryConfig, security SecurityConfig) (*Consumer, error) {
+	dialer, err := buildDialer(context.Background(), security)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Kafka authentication: %w", err)
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        brokers,
 		Topic:          topic,
@@ -29,12 +47,15 @@ func NewConsumer(brokers []string, topic, groupID string, proc processor.Process
 		MaxBytes:       10e6, // 10MB
 		MaxWait:        1 * time.Second,
 		CommitInterval: time.Second,
+		Dialer:         dialer,
 	})
 
 	return &Consumer{
-		reader:    reader,
-		processor: proc,
-	}
+		reader:      reader,
+		processor:   proc,
+		dlqProducer: dlqProducer,
+		retryCfg:    retryCfg,
+	}, nil
 }
 
 // SetOnMessageProcessed sets a callback to be called after each message is processed
@@ -42,14 +63,19 @@ func (c *Consumer) SetOnMessageProcessed(callback func()) {
 	c.onMessageProcessed = callback
 }
 
+// SetOnDLQ sets a callback to be called whenever a message is published to the DLQ
+func (c *Consumer) SetOnDLQ(callback func()) {
+	c.onDLQ = callback
+}
+
 // Start begins consuming messages from Kafka
 func (c *Consumer) Start(ctx context.Context) error {
-	log.Printf("[CONSUMER] Запуск consumer, подключение к топику: %s", c.reader.Config().Topic)
+	logger.FromContext(ctx).Info("Starting Kafka consumer", "topic", c.reader.Config().Topic)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("[CONSUMER] Получен сигнал остановки, завершение работы...")
+			logger.FromContext(ctx).Info("Received stop signal, shutting down consumer")
 			return c.Close()
 		default:
 			msg, err := c.reader.FetchMessage(ctx)
@@ -57,44 +83,157 @@ func (c *Consumer) Start(ctx context.Context) error {
 				if ctx.Err() != nil {
 					return nil // Context cancelled, graceful shutdown
 				}
-				log.Printf("[CONSUMER] Ошибка чтения сообщения: %v", err)
+				logger.FromContext(ctx).Error("Error fetching message", "error", err)
 				continue
 			}
 
-			log.Printf("[CONSUMER] Получено сообщение: partition=%d, offset=%d, key=%s",
-				msg.Partition, msg.Offset, string(msg.Key))
+			log := logger.FromContext(ctx).With(
+				"topic", c.reader.Config().Topic,
+				"partition", msg.Partition,
+				"offset", msg.Offset,
+				"key", string(msg.Key),
+				"trace_id", traceIDOf(msg),
+			)
+			msgCtx := logger.WithContext(ctx, log)
 
-			// Deserialize message
-			var cmd models.ImageCommand
-			if err := json.Unmarshal(msg.Value, &cmd); err != nil {
-				log.Printf("[CONSUMER] Ошибка десериализации JSON: %v", err)
+			log.Debug("Received message")
+			firstSeen := time.Now()
+
+			// Deserialize message using the codec negotiated via the
+			// content-type header (defaults to protobuf)
+			codec := codecForFormat(contentTypeOf(msg.Headers))
+			cmd, err := codec.Decode(msg.Value)
+			if err != nil {
+				log.Error("Message deserialization error", "error", err)
+				metrics.RecordKafkaMessage(c.reader.Config().Topic, "error")
+				metrics.RecordProcessorError(customerrors.ErrCodeBadRequest)
+				c.sendToDLQ(msgCtx, msg, customerrors.ErrCodeBadRequest, 0, firstSeen)
 				// Commit message even on error to avoid infinite retry
 				if err := c.reader.CommitMessages(ctx, msg); err != nil {
-					log.Printf("[CONSUMER] Ошибка коммита сообщения: %v", err)
+					log.Error("Commit error", "error", err)
 				}
 				continue
 			}
 
-			// Process the command
-			if err := c.processor.Process(cmd); err != nil {
-				log.Printf("[CONSUMER] Ошибка обработки команды %s: %v", cmd.ID, err)
+			log = log.With("message_id", cmd.Id)
+			msgCtx = logger.WithContext(msgCtx, log)
+
+			// Extract the producer's span context from the message headers
+			// so processing runs as a child span of the SendHandler span.
+			msgCtx = otel.GetTextMapPropagator().Extract(msgCtx, HeaderCarrier{Headers: &msg.Headers})
+			msgCtx, span := tracing.StartSpan(msgCtx, "Consumer.Process")
+			tracing.SetAttributes(msgCtx, tracing.KafkaAttributes(c.reader.Config().Topic, msg.Partition, msg.Offset)...)
+
+			stats := c.reader.Stats()
+			metrics.SetConsumerLag(c.reader.Config().Topic, int32(msg.Partition), float64(stats.Lag))
+
+			// Process the command, retrying transient failures with backoff
+			// and jitter, and failing fast to the DLQ for terminal errors.
+			maxAttempts := c.retryCfg.MaxAttempts
+			if maxAttempts < 1 {
+				maxAttempts = 1
+			}
+
+			processingStart := time.Now()
+			var procErr error
+			var errorCode string
+			attempt := 1
+			for ; attempt <= maxAttempts; attempt++ {
+				procErr = c.processor.Process(msgCtx, cmd)
+				if procErr == nil {
+					break
+				}
+
+				errorCode = errorCodeOf(procErr)
+				log.Error("Command processing error", "error", procErr, "attempt", attempt, "max_attempts", maxAttempts)
+				if attempt == maxAttempts || !isRetryable(procErr) {
+					break
+				}
+
+				select {
+				case <-time.After(c.retryCfg.backoffDuration(attempt)):
+				case <-ctx.Done():
+					span.End()
+					return ctx.Err()
+				}
+			}
+
+			metrics.ObserveKafkaMessageProcessingDuration(cmd.Command.String(), time.Since(processingStart).Seconds())
+
+			if procErr != nil {
+				tracing.RecordError(msgCtx, procErr)
+				metrics.RecordKafkaMessage(c.reader.Config().Topic, "error")
+				metrics.RecordProcessorError(errorCode)
+				c.sendToDLQ(msgCtx, msg, errorCode, attempt, firstSeen)
 			} else {
-				// Call callback on successful processing
+				metrics.RecordKafkaMessage(c.reader.Config().Topic, "success")
 				if c.onMessageProcessed != nil {
+					// Call callback on successful processing
 					c.onMessageProcessed()
 				}
 			}
+			span.End()
 
 			// Commit the message
 			if err := c.reader.CommitMessages(ctx, msg); err != nil {
-				log.Printf("[CONSUMER] Ошибка коммита сообщения: %v", err)
+				log.Error("Commit error", "error", err)
 			}
 		}
 	}
 }
 
+// sendToDLQ republishes a failed message to the configured dead-letter
+// topic, if one is set, along with enough metadata to diagnose or replay it.
+func (c *Consumer) sendToDLQ(ctx context.Context, msg kafka.Message, errorCode string, attempts int, firstSeen time.Time) {
+	if c.dlqProducer == nil {
+		return
+	}
+
+	err := c.dlqProducer.PublishFailure(ctx, msg.Key, msg.Value, FailureMetadata{
+		OriginalTopic: c.reader.Config().Topic,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		ErrorCode:     errorCode,
+		Attempts:      attempts,
+		FirstSeen:     firstSeen,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to publish message to DLQ", "error", err)
+		return
+	}
+	if c.onDLQ != nil {
+		c.onDLQ()
+	}
+}
+
+// traceIDOf looks for an incoming trace_id Kafka header (e.g. propagated by
+// an upstream producer), generating a fresh one if the message carries none,
+// so every log line for this message can still be correlated by trace_id.
+func traceIDOf(msg kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == "trace_id" && len(h.Value) > 0 {
+			return string(h.Value)
+		}
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// errorCodeOf extracts the customerrors.AppError code from err, falling
+// back to the generic internal-error code for unclassified errors.
+func errorCodeOf(err error) string {
+	var appErr *customerrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return customerrors.ErrCodeInternal
+}
+
 // Close closes the Kafka consumer
 func (c *Consumer) Close() error {
-	log.Println("[CONSUMER] Закрытие соединения с Kafka...")
+	logger.Info("Closing Kafka connection")
 	return c.reader.Close()
 }
@@ -0,0 +1,107 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"kafka-consumer/internal/pkg/logger"
+)
+
+// DLQProducer republishes raw Kafka messages that could not be decoded or
+// processed to a dead-letter topic, annotating them with enough headers to
+// diagnose and potentially replay the failure later.
+type DLQProducer struct {
+	writer *kafka.Writer
+}
+
+// NewDLQProducer creates a producer writing to the given dead-letter topic
+func NewDLQProducer(brokers []string, topic string) *DLQProducer {
+	return &DLQProducer{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+// Send republishes the original message value along with failure metadata
+// headers: x-original-topic/partition/offset identify where it came from,
+// x-attempts how many processing attempts it went through, x-first-seen
+// when the handler first took possession of it, x-failed-at when it was
+// finally given up on, and x-error the cause, if any.
+func (p *DLQProducer) Send(ctx context.Context, originalTopic string, partition int, offset int64, key, value []byte, causeErr error, attempts int, firstSeen time.Time) error {
+	headers := []kafka.Header{
+		{Key: "x-original-topic", Value: []byte(originalTopic)},
+		{Key: "x-original-partition", Value: []byte(strconv.Itoa(partition))},
+		{Key: "x-original-offset", Value: []byte(strconv.FormatInt(offset, 10))},
+		{Key: "x-attempts", Value: []byte(strconv.Itoa(attempts))},
+		{Key: "x-first-seen", Value: []byte(firstSeen.UTC().Format(time.RFC3339Nano))},
+		{Key: "x-failed-at", Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	}
+	if causeErr != nil {
+		headers = append(headers, kafka.Header{Key: "x-error", Value: []byte(causeErr.Error())})
+	}
+
+	msg := kafka.Message{
+		Key:     key,
+		Value:   value,
+		Headers: headers,
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		logger.Error("Failed to send message to DLQ", "topic", p.writer.Topic, "error", err)
+		return err
+	}
+
+	logger.Warn("Sent message to DLQ", "topic", p.writer.Topic, "original_topic", originalTopic,
+		"partition", partition, "offset", offset, "attempts", attempts)
+	return nil
+}
+
+// FailureMetadata captures the context needed to diagnose and potentially
+// replay a message that hit a terminal error or exhausted its retries.
+type FailureMetadata struct {
+	OriginalTopic string
+	Partition     int
+	Offset        int64
+	ErrorCode     string
+	Attempts      int
+	FirstSeen     time.Time
+}
+
+// PublishFailure republishes the original message bytes to the dead-letter
+// topic, annotated with the error code, attempt count, original
+// topic/partition/offset and the time the message was first seen.
+func (p *DLQProducer) PublishFailure(ctx context.Context, key, value []byte, meta FailureMetadata) error {
+	msg := kafka.Message{
+		Key:   key,
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "x-original-topic", Value: []byte(meta.OriginalTopic)},
+			{Key: "x-original-partition", Value: []byte(strconv.Itoa(meta.Partition))},
+			{Key: "x-original-offset", Value: []byte(strconv.FormatInt(meta.Offset, 10))},
+			{Key: "x-error-code", Value: []byte(meta.ErrorCode)},
+			{Key: "x-attempts", Value: []byte(strconv.Itoa(meta.Attempts))},
+			{Key: "x-first-seen", Value: []byte(meta.FirstSeen.UTC().Format(time.RFC3339Nano))},
+			{Key: "x-failed-at", Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+		},
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		logger.Error("Failed to send message to DLQ", "topic", p.writer.Topic, "error", err)
+		return err
+	}
+
+	logger.Warn("Sent message to DLQ", "topic", p.writer.Topic, "original_topic", meta.OriginalTopic,
+		"partition", meta.Partition, "offset", meta.Offset, "attempts", meta.Attempts, "error_code", meta.ErrorCode)
+	return nil
+}
+
+// Close closes the underlying writer
+func (p *DLQProducer) Close() error {
+	return p.writer.Close()
+}
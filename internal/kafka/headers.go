@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"github.com/segmentio/kafka-go"
+)
+
+// HeaderCarrier adapts a *[]kafka.Header to otel's propagation.TextMapCarrier,
+// so trace context can be injected into and extracted from Kafka message
+// headers. Headers must be addressed through a pointer since Set appends to
+// the slice.
+type HeaderCarrier struct {
+	Headers *[]kafka.Header
+}
+
+// Get returns the value of the first header with the given key, or "" if none.
+func (c HeaderCarrier) Get(key string) string {
+	for _, h := range *c.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the value of an existing header with the given key, or
+// appends a new one if none is present.
+func (c HeaderCarrier) Set(key, value string) {
+	for i, h := range *c.Headers {
+		if h.Key == key {
+			(*c.Headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.Headers = append(*c.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys returns all header keys currently set.
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, len(*c.Headers))
+	for i, h := range *c.Headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
@@ -2,54 +2,172 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"encoding/binary"
+	"fmt"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 
-	"kafka-consumer/internal/models"
+	"kafka-consumer/internal/pkg/logger"
+	"kafka-consumer/internal/pkg/schemaregistry"
+	"kafka-consumer/internal/pkg/tracing"
+	pb "kafka-consumer/proto"
 )
 
+// confluentMagicByte is the leading byte of the Confluent wire format, which
+// is followed by a 4-byte big-endian schema ID. Kept in sync with the
+// decoder-side constant in internal/delivery/queue.
+const confluentMagicByte = 0x00
+
 // Producer represents a Kafka producer for sending image commands
 type Producer struct {
-	writer *kafka.Writer
+	writer   *kafka.Writer
+	registry *schemaregistry.Client
+	format   MessageFormat
 }
 
-// NewProducer creates a new Kafka producer
-func NewProducer(brokers []string, topic string) *Producer {
+// NewProducer creates a new Kafka producer. Messages are encoded as
+// protobuf by default; use SetMessageFormat to negotiate JSON instead.
+// security may be the zero value for a plaintext, unauthenticated
+// connection; it returns an error if the configured SASL mechanism or TLS
+// material is invalid, so callers can fail fast at startup.
+func NewProducer(brokers []string, topic string, security SecurityConfig) (*Producer, error) {
+	transport, err := buildTransport(context.Background(), security)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Kafka authentication: %w", err)
+	}
+
 	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Addr:      kafka.TCP(brokers...),
+		Topic:     topic,
+		Balancer:  &kafka.LeastBytes{},
+		Transport: transport,
 	}
 
 	return &Producer{
 		writer: writer,
+		format: FormatProtobuf,
+	}, nil
+}
+
+// SetMessageFormat selects the wire encoding used by SendMessage, advertised
+// to consumers via the "content-type" header.
+func (p *Producer) SetMessageFormat(format MessageFormat) {
+	p.format = format
+}
+
+// SetSchemaRegistry enables Confluent wire-format encoding via PublishProto,
+// registering/looking up the writer schema against the given registry client.
+func (p *Producer) SetSchemaRegistry(registry *schemaregistry.Client) {
+	p.registry = registry
+}
+
+// SetSASL authenticates the producer's connections using the given SASL
+// mechanism, e.g. an OAUTHBEARER mechanism from internal/pkg/auth. If the
+// writer already has a *kafka.Transport (e.g. one built by buildTransport
+// with TLS configured), its SASL field is updated in place so TLS settings
+// aren't discarded; otherwise a bare transport with just SASL is created.
+func (p *Producer) SetSASL(mechanism sasl.Mechanism) {
+	if t, ok := p.writer.Transport.(*kafka.Transport); ok && t != nil {
+		t.SASL = mechanism
+		return
 	}
+	p.writer.Transport = &kafka.Transport{SASL: mechanism}
 }
 
-// SendMessage sends an image command to Kafka
-func (p *Producer) SendMessage(ctx context.Context, cmd models.ImageCommand) error {
-	data, err := json.Marshal(cmd)
+// SendMessage encodes cmd using the producer's negotiated MessageFormat,
+// stamps the content-type header so consumers know how to decode it, and
+// injects the current span context into the message headers so the
+// consumer's processing runs as a child span of the caller's.
+func (p *Producer) SendMessage(ctx context.Context, cmd *pb.ImageCommand) error {
+	ctx, span := tracing.StartSpan(ctx, "Producer.SendMessage")
+	defer span.End()
+	tracing.SetAttributes(ctx, tracing.KafkaAttributes(p.writer.Topic, -1, -1)...)
+
+	codec := codecForFormat(p.format)
+	data, err := codec.Encode(cmd)
 	if err != nil {
+		err = fmt.Errorf("failed to encode command: %w", err)
+		tracing.RecordError(ctx, err)
 		return err
 	}
 
 	msg := kafka.Message{
-		Key:   []byte(cmd.ID),
+		Key:   []byte(cmd.Id),
 		Value: data,
+		Headers: []kafka.Header{
+			{Key: contentTypeHeader, Value: []byte(codec.ContentType())},
+		},
+	}
+	otel.GetTextMapPropagator().Inject(ctx, HeaderCarrier{Headers: &msg.Headers})
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		tracing.RecordError(ctx, err)
+		logger.FromContext(ctx).Error("Failed to send message", "error", err)
+		return err
 	}
 
-	err = p.writer.WriteMessages(ctx, msg)
+	logger.FromContext(ctx).Info("Message sent", "id", cmd.Id, "command", cmd.Command)
+	return nil
+}
+
+// PublishProto serializes cmd as protobuf and writes it to Kafka. If a
+// schema registry client is configured, the payload is prefixed with the
+// Confluent wire format (magic byte + 4-byte schema ID) using the writer
+// schema registered under the producer's topic subject.
+func (p *Producer) PublishProto(ctx context.Context, key string, cmd *pb.ImageCommand) error {
+	payload, err := proto.Marshal(cmd)
 	if err != nil {
-		log.Printf("[PRODUCER] Ошибка отправки сообщения: %v", err)
+		return fmt.Errorf("failed to marshal command: %w", err)
+	}
+
+	value := payload
+	if p.registry != nil {
+		value, err = p.encodeSchemaRegistry(ctx, payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		logger.FromContext(ctx).Error("Failed to send message", "error", err)
 		return err
 	}
 
-	log.Printf("[PRODUCER] Сообщение отправлено: ID=%s, Command=%s", cmd.ID, cmd.Command)
+	logger.FromContext(ctx).Info("Message sent", "id", cmd.Id, "command", cmd.Command)
 	return nil
 }
 
+// encodeSchemaRegistry registers (or looks up) the writer schema and
+// prepends the Confluent wire-format prefix to the already-serialized payload.
+func (p *Producer) encodeSchemaRegistry(ctx context.Context, payload []byte) ([]byte, error) {
+	fileDesc := protodesc.ToFileDescriptorProto((&pb.ImageCommand{}).ProtoReflect().Descriptor().ParentFile())
+	descriptor, err := proto.Marshal(fileDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build schema descriptor: %w", err)
+	}
+
+	subject := p.registry.Subject(p.writer.Topic, "ImageCommand")
+	schemaID, err := p.registry.Register(ctx, subject, schemaregistry.SchemaTypeProtobuf, string(descriptor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register schema: %w", err)
+	}
+
+	buf := make([]byte, 5+len(payload))
+	buf[0] = confluentMagicByte
+	binary.BigEndian.PutUint32(buf[1:5], uint32(schemaID))
+	copy(buf[5:], payload)
+	return buf, nil
+}
+
 // Close closes the Kafka producer
 func (p *Producer) Close() error {
 	return p.writer.Close()
@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"kafka-consumer/internal/pkg/customerrors"
+)
+
+// RetryConfig controls how many times Consumer retries a failed command
+// before giving up and publishing it to the dead-letter topic.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// backoffDuration returns an exponential backoff with +/-20% jitter for the
+// given attempt number (1-indexed).
+func (r RetryConfig) backoffDuration(attempt int) time.Duration {
+	d := r.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+	}
+
+	jitter := 0.8 + rand.Float64()*0.4 // 0.8x - 1.2x
+	return time.Duration(float64(d) * jitter)
+}
+
+// isRetryable classifies an error as transient (worth another attempt) or
+// terminal (fail straight to the DLQ). Validation/bad-command errors can
+// never succeed on retry; anything else (decode failures, network/timeout,
+// processor errors) is treated as retryable up to MaxAttempts.
+func isRetryable(err error) bool {
+	var appErr *customerrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case customerrors.ErrCodeValidation, customerrors.ErrCodeBadRequest:
+			return false
+		}
+	}
+	return true
+}
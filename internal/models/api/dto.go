@@ -17,14 +17,16 @@ type ReadyResponse struct {
 type StatsResponse struct {
 	UptimeSeconds     float64 `json:"uptime_seconds"`
 	MessagesProcessed int64   `json:"messages_processed"`
+	ConsumerState     string  `json:"consumer_state,omitempty"`
 }
 
 // SendMessageRequest represents a request to send a message to Kafka
 type SendMessageRequest struct {
-	ID         string                 `json:"id" validate:"required"`
-	Command    string                 `json:"command" validate:"required,oneof=resize filter transform analyze crop remove_background"`
-	ImageURL   string                 `json:"image_url" validate:"required,url"`
-	Parameters map[string]interface{} `json:"parameters"`
+	ID          string                 `json:"id" validate:"required"`
+	Command     string                 `json:"command" validate:"required,oneof=resize filter transform analyze crop remove_background"`
+	ImageURL    string                 `json:"image_url" validate:"required,url"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	CallbackURL string                 `json:"callback_url,omitempty" validate:"omitempty,url"`
 }
 
 // SendMessageResponse represents the response after sending a message
@@ -33,3 +35,14 @@ type SendMessageResponse struct {
 	Message string `json:"message"`
 	ID      string `json:"id,omitempty"`
 }
+
+// JobStatusResponse represents the current state of an asynchronously
+// processed command, as returned by GET /jobs/{id} and GET /jobs.
+type JobStatusResponse struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,68 @@
+// Package auth provides OAuth2 client-credentials authentication shared by
+// the Kafka SASL OAUTHBEARER mechanism and outbound HTTP clients (puller
+// providers, schema registry, etc.), so both speak to the same identity
+// provider through one token source.
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"kafka-consumer/internal/pkg/metrics"
+)
+
+// Config holds OAuth2 client-credentials settings
+type Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Audience     string
+}
+
+// NewTokenSource builds a token source that transparently fetches and caches
+// client-credentials tokens, refreshing them as they expire, and records
+// fetch latency/failure metrics on every underlying fetch.
+func NewTokenSource(ctx context.Context, cfg Config) oauth2.TokenSource {
+	ccCfg := &clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     cfg.TokenURL,
+		Scopes:       cfg.Scopes,
+	}
+	if cfg.Audience != "" {
+		ccCfg.EndpointParams = map[string][]string{"audience": {cfg.Audience}}
+	}
+	return oauth2.ReuseTokenSource(nil, &instrumentedTokenSource{ctx: ctx, cfg: ccCfg})
+}
+
+// instrumentedTokenSource fetches a new token from the token endpoint on
+// every Token() call, recording fetch latency and failures. NewTokenSource
+// wraps it in oauth2.ReuseTokenSource, which caches until near-expiry, so
+// Token() is only called on an actual fetch/refresh.
+type instrumentedTokenSource struct {
+	ctx context.Context
+	cfg *clientcredentials.Config
+}
+
+func (s *instrumentedTokenSource) Token() (*oauth2.Token, error) {
+	start := time.Now()
+	tok, err := s.cfg.Token(s.ctx)
+	metrics.RecordOAuthTokenFetch(time.Since(start).Seconds(), err)
+	return tok, err
+}
+
+// HTTPClient returns an *http.Client that injects "Authorization: Bearer
+// <token>" into every outbound request, fetching/refreshing via ts.
+func HTTPClient(ts oauth2.TokenSource) *http.Client {
+	return &http.Client{
+		Transport: &oauth2.Transport{
+			Base:   http.DefaultTransport,
+			Source: ts,
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFakeTokenServer returns an httptest server that issues client-credentials
+// tokens, incrementing calls each time a token is issued so tests can assert
+// on caching/refresh behavior.
+func newFakeTokenServer(t *testing.T, calls *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse token request: %v", err)
+		}
+		if r.FormValue("grant_type") != "client_credentials" {
+			http.Error(w, "unsupported grant type", http.StatusBadRequest)
+			return
+		}
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fake-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+}
+
+func TestNewTokenSource_FetchesToken(t *testing.T) {
+	var calls int
+	server := newFakeTokenServer(t, &calls)
+	defer server.Close()
+
+	ts := NewTokenSource(context.Background(), Config{
+		TokenURL:     server.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Scopes:       []string{"images.read"},
+	})
+
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() returned error: %v", err)
+	}
+	if tok.AccessToken != "fake-token" {
+		t.Errorf("Expected access token 'fake-token', got '%s'", tok.AccessToken)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 token fetch, got %d", calls)
+	}
+
+	// A second call should be served from the cached, unexpired token.
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("second Token() returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected cached token to avoid a second fetch, got %d calls", calls)
+	}
+}
+
+func TestHTTPClient_InjectsBearerToken(t *testing.T) {
+	var calls int
+	tokenServer := newFakeTokenServer(t, &calls)
+	defer tokenServer.Close()
+
+	var gotAuth string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiServer.Close()
+
+	ts := NewTokenSource(context.Background(), Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	})
+
+	client := HTTPClient(ts)
+	resp, err := client.Get(apiServer.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAuth != "Bearer fake-token" {
+		t.Errorf("Expected 'Bearer fake-token' Authorization header, got '%s'", gotAuth)
+	}
+}
+
+func TestNewSASLMechanism_Start(t *testing.T) {
+	var calls int
+	server := newFakeTokenServer(t, &calls)
+	defer server.Close()
+
+	ts := NewTokenSource(context.Background(), Config{
+		TokenURL:     server.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	})
+
+	mechanism := NewSASLMechanism(ts)
+	if mechanism.Name() != "OAUTHBEARER" {
+		t.Errorf("Expected mechanism name 'OAUTHBEARER', got '%s'", mechanism.Name())
+	}
+
+	session, ir, err := mechanism.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	if session == nil {
+		t.Fatal("Expected non-nil session")
+	}
+
+	expected := "n,,\x01auth=Bearer fake-token\x01\x01"
+	if string(ir) != expected {
+		t.Errorf("Expected initial response %q, got %q", expected, string(ir))
+	}
+
+	done, resp, err := session.Next(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if !done {
+		t.Error("Expected handshake to complete on empty challenge")
+	}
+	if resp != nil {
+		t.Errorf("Expected nil response on success, got %v", resp)
+	}
+}
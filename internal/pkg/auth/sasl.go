@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go/sasl"
+	"golang.org/x/oauth2"
+)
+
+// oauthBearerMechanism implements Kafka's SASL/OAUTHBEARER mechanism
+// (RFC 7628), fetching a fresh bearer token from a TokenSource on every
+// handshake.
+type oauthBearerMechanism struct {
+	tokenSource oauth2.TokenSource
+}
+
+// NewSASLMechanism builds a sasl.Mechanism that authenticates Kafka
+// connections using OAUTHBEARER tokens sourced from ts.
+func NewSASLMechanism(ts oauth2.TokenSource) sasl.Mechanism {
+	return &oauthBearerMechanism{tokenSource: ts}
+}
+
+// Name implements sasl.Mechanism
+func (m *oauthBearerMechanism) Name() string { return "OAUTHBEARER" }
+
+// Start implements sasl.Mechanism, sending the initial OAUTHBEARER response
+func (m *oauthBearerMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	tok, err := m.tokenSource.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch OAuth token: %w", err)
+	}
+	ir := []byte(fmt.Sprintf("n,,\x01auth=Bearer %s\x01\x01", tok.AccessToken))
+	return &oauthBearerSession{}, ir, nil
+}
+
+// oauthBearerSession completes the single-round-trip OAUTHBEARER handshake.
+type oauthBearerSession struct{}
+
+// Next implements sasl.StateMachine. A non-empty challenge means the server
+// rejected the token; per RFC 7628 3.1 the client must ack with an empty
+// message to terminate the exchange rather than retry indefinitely here.
+func (s *oauthBearerSession) Next(ctx context.Context, challenge []byte) (bool, []byte, error) {
+	if len(challenge) == 0 {
+		return true, nil, nil
+	}
+	return true, []byte{}, nil
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
@@ -60,3 +61,24 @@ func Error(msg string, args ...any) {
 func With(args ...any) *slog.Logger {
 	return Logger.With(args...)
 }
+
+// contextKey is an unexported type to avoid collisions with context keys
+// defined in other packages
+type contextKey struct{}
+
+var loggerKey = contextKey{}
+
+// WithContext returns a copy of ctx carrying l as its request/message-scoped
+// logger, retrievable later via FromContext
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in ctx by WithContext, or the global
+// Logger if ctx carries none
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok && l != nil {
+		return l
+	}
+	return Logger
+}
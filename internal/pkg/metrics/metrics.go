@@ -2,12 +2,23 @@ package metrics
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 )
 
+// FastBuckets suits sub-second operations like HTTP handlers and token
+// fetches.
+var FastBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// ImageBuckets suits image-processing durations, which commonly run
+// 100ms-2s and occasionally much longer for large pipelines; DefBuckets'
+// 5ms starting point undercounts almost everything this service does.
+var ImageBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
 var (
 	// MessagesProcessedTotal tracks total number of messages processed
 	MessagesProcessedTotal = promauto.NewCounterVec(
@@ -23,7 +34,7 @@ var (
 		prometheus.HistogramOpts{
 			Name:    "kafka_consumer_message_processing_duration_seconds",
 			Help:    "Duration of message processing in seconds",
-			Buckets: prometheus.DefBuckets,
+			Buckets: ImageBuckets,
 		},
 		[]string{"command"},
 	)
@@ -55,8 +66,136 @@ var (
 		},
 		[]string{"method", "endpoint"},
 	)
+
+	// RetriesTotal tracks retry attempts before a message either succeeds or goes to the DLQ
+	RetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_retries_total",
+			Help: "Total number of message processing retries",
+		},
+		[]string{"command"},
+	)
+
+	// DLQTotal tracks messages republished to the dead-letter topic
+	DLQTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_dlq_total",
+			Help: "Total number of messages sent to the dead-letter queue",
+		},
+		[]string{"reason"},
+	)
+
+	// PullerCacheTotal tracks puller disk-cache hits and misses
+	PullerCacheTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_puller_cache_total",
+			Help: "Total number of puller cache lookups by result",
+		},
+		[]string{"result"},
+	)
+
+	// PullerBytesTransferred tracks bytes pulled from source providers
+	PullerBytesTransferred = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_puller_bytes_transferred_total",
+			Help: "Total bytes transferred by the puller subsystem",
+		},
+	)
+
+	// OAuthTokenFetchDuration tracks OAuth2 client-credentials token fetch/refresh latency
+	OAuthTokenFetchDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "kafka_consumer_oauth_token_fetch_duration_seconds",
+			Help:    "Duration of OAuth2 client-credentials token fetches in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	// OAuthTokenFetchFailuresTotal tracks failed OAuth2 token fetches
+	OAuthTokenFetchFailuresTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_oauth_token_fetch_failures_total",
+			Help: "Total number of failed OAuth2 token fetches",
+		},
+	)
+
+	// APISendRequestsTotal tracks /send requests handled by the API, by
+	// command and outcome. StatsResponse/HealthResponse read their
+	// messages-processed counter from this, so a single increment shows up
+	// in both the JSON stats and a Prometheus scrape.
+	APISendRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_send_requests_total",
+			Help: "Total number of /send requests handled by the API",
+		},
+		[]string{"command", "result"},
+	)
+
+	// APISendDuration tracks how long /send takes to hand a command off to Kafka
+	APISendDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "api_send_duration_seconds",
+			Help:    "Duration of /send requests in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"command"},
+	)
+
+	// KafkaMessagesTotal tracks messages consumed by the legacy kafka.Consumer, by topic and outcome
+	KafkaMessagesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_messages_total",
+			Help: "Total number of messages consumed, by topic and outcome",
+		},
+		[]string{"topic", "result"},
+	)
+
+	// KafkaMessageProcessingDuration tracks processing duration in the legacy kafka.Consumer
+	KafkaMessageProcessingDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_message_processing_duration_seconds",
+			Help:    "Duration of command processing in the legacy Kafka consumer, in seconds",
+			Buckets: ImageBuckets,
+		},
+		[]string{"command"},
+	)
+
+	// ProcessorErrorsTotal tracks processing failures by their customerrors.AppError code
+	ProcessorErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "processor_errors_total",
+			Help: "Total number of processing failures, by error code",
+		},
+		[]string{"code"},
+	)
+
+	// KafkaConsumerState tracks the new-path queue.Handler's consume-loop
+	// state (0=disconnected, 1=connecting, 2=running, 3=rebalancing,
+	// 4=stopped), by partition
+	KafkaConsumerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_consumer_state",
+			Help: "Current state of the Kafka consumer loop (0=disconnected, 1=connecting, 2=running, 3=rebalancing, 4=stopped), by partition",
+		},
+		[]string{"partition"},
+	)
+
+	// PipelineStepDuration tracks how long each step of a
+	// COMMAND_TYPE_PIPELINE command takes, by step type
+	PipelineStepDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_consumer_pipeline_step_duration_seconds",
+			Help:    "Duration of a single pipeline step in seconds, by step type",
+			Buckets: ImageBuckets,
+		},
+		[]string{"step"},
+	)
 )
 
+// apiSendCommands lists every command label value SendHandler records
+// successes under, so TotalAPISendRequests can sum across all of them.
+var apiSendCommands = []string{"resize", "filter", "transform", "analyze", "crop", "remove_background", "unknown"}
+
 // Handler returns the Prometheus metrics handler
 func Handler() http.Handler {
 	return promhttp.Handler()
@@ -74,5 +213,125 @@ func ObserveMessageProcessingDuration(command string, duration float64) {
 
 // SetConsumerLag sets the consumer lag for a partition
 func SetConsumerLag(topic string, partition int32, lag float64) {
-	KafkaConsumerLag.WithLabelValues(topic, string(rune(partition))).Set(lag)
+	KafkaConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(lag)
+}
+
+// RecordRetry records a processing retry for a command type
+func RecordRetry(command string) {
+	RetriesTotal.WithLabelValues(command).Inc()
+}
+
+// RecordDLQSend records a message being sent to the dead-letter topic, keyed by reason
+func RecordDLQSend(reason string) {
+	DLQTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordPullerCacheResult records a puller cache hit or miss
+func RecordPullerCacheResult(result string) {
+	PullerCacheTotal.WithLabelValues(result).Inc()
+}
+
+// RecordPullerBytes adds to the total bytes transferred by the puller
+func RecordPullerBytes(n int64) {
+	if n > 0 {
+		PullerBytesTransferred.Add(float64(n))
+	}
+}
+
+// RecordOAuthTokenFetch records the latency of an OAuth2 token fetch and,
+// when err is non-nil, counts it as a failure
+func RecordOAuthTokenFetch(duration float64, err error) {
+	OAuthTokenFetchDuration.Observe(duration)
+	if err != nil {
+		OAuthTokenFetchFailuresTotal.Inc()
+	}
+}
+
+// RecordAPISendRequest records the outcome of a /send request for a command
+func RecordAPISendRequest(command, result string) {
+	APISendRequestsTotal.WithLabelValues(command, result).Inc()
+}
+
+// ObserveAPISendDuration records how long a /send request took to hand its
+// command off to Kafka
+func ObserveAPISendDuration(command string, duration float64) {
+	APISendDuration.WithLabelValues(command).Observe(duration)
+}
+
+// TotalAPISendRequests returns the number of successful /send requests
+// recorded so far, summed across every command type. StatsHandler and
+// HealthHandler read from this instead of keeping their own counter, so the
+// same increment is visible in both the JSON stats and a Prometheus scrape.
+func TotalAPISendRequests() int64 {
+	var total float64
+	for _, command := range apiSendCommands {
+		total += counterValue(APISendRequestsTotal.WithLabelValues(command, "success"))
+	}
+	return int64(total)
+}
+
+// counterValue reads the current value of a single Prometheus counter
+// without going through a full registry gather.
+func counterValue(c prometheus.Counter) float64 {
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		return 0
+	}
+	return m.GetCounter().GetValue()
+}
+
+// RecordKafkaMessage records a message consumed by the legacy kafka.Consumer
+func RecordKafkaMessage(topic, result string) {
+	KafkaMessagesTotal.WithLabelValues(topic, result).Inc()
+}
+
+// ObserveKafkaMessageProcessingDuration records how long the legacy
+// kafka.Consumer spent processing a command
+func ObserveKafkaMessageProcessingDuration(command string, duration float64) {
+	KafkaMessageProcessingDuration.WithLabelValues(command).Observe(duration)
+}
+
+// RecordProcessorError records a processing failure by its
+// customerrors.AppError code
+func RecordProcessorError(code string) {
+	ProcessorErrorsTotal.WithLabelValues(code).Inc()
+}
+
+// SetConsumerState sets the kafka_consumer_state gauge for a partition
+func SetConsumerState(partition string, value float64) {
+	KafkaConsumerState.WithLabelValues(partition).Set(value)
+}
+
+// ObservePipelineStepDuration records how long a single pipeline step took
+func ObservePipelineStepDuration(step string, duration float64) {
+	PipelineStepDuration.WithLabelValues(step).Observe(duration)
+}
+
+// Recorder is the subset of this package's recording behavior
+// RealImageProcessor depends on, extracted so tests can inject a fake and
+// assert on observations without touching this package's global
+// Prometheus collectors.
+type Recorder interface {
+	RecordMessageProcessed(command, status string)
+	ObserveMessageProcessingDuration(command string, duration float64)
+	ObservePipelineStepDuration(step string, duration float64)
+}
+
+// DefaultRecorder implements Recorder by calling this package's
+// package-level functions, i.e. recording through the global collectors.
+type DefaultRecorder struct{}
+
+// RecordMessageProcessed implements Recorder.
+func (DefaultRecorder) RecordMessageProcessed(command, status string) {
+	RecordMessageProcessed(command, status)
+}
+
+// ObserveMessageProcessingDuration implements Recorder.
+func (DefaultRecorder) ObserveMessageProcessingDuration(command string, duration float64) {
+	ObserveMessageProcessingDuration(command, duration)
+}
+
+// ObservePipelineStepDuration implements Recorder.
+func (DefaultRecorder) ObservePipelineStepDuration(step string, duration float64) {
+	ObservePipelineStepDuration(step, duration)
 }
@@ -0,0 +1,181 @@
+// Package schemaregistry is a minimal client for a Confluent-compatible
+// Schema Registry, used to resolve the schema ID embedded in the Confluent
+// wire format (a 5-byte prefix: magic byte 0x00 + big-endian schema ID).
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SchemaType identifies the serialization format a registered schema describes
+type SchemaType string
+
+const (
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeAvro     SchemaType = "AVRO"
+)
+
+// Schema is a resolved schema as returned by the registry
+type Schema struct {
+	ID      int
+	Type    SchemaType
+	Raw     string // the raw schema as registered: Avro JSON, or for PROTOBUF the string kafka.Producer registers (a serialized FileDescriptorProto)
+	Subject string
+}
+
+// Config holds the settings needed to reach a schema registry
+type Config struct {
+	URL             string
+	Username        string
+	Password        string
+	SubjectStrategy string // "topic_name" (default), "record_name", "topic_record_name"
+	CacheTTL        time.Duration
+}
+
+// Client resolves and caches schemas by ID, and can register new ones
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	cache map[int]cacheEntry
+}
+
+type cacheEntry struct {
+	schema    *Schema
+	expiresAt time.Time
+}
+
+// NewClient creates a schema registry client
+func NewClient(cfg Config) *Client {
+	if cfg.SubjectStrategy == "" {
+		cfg.SubjectStrategy = "topic_name"
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = 10 * time.Minute
+	}
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cache:      make(map[int]cacheEntry),
+	}
+}
+
+// Subject computes the registry subject name for a topic, following the
+// configured naming strategy.
+func (c *Client) Subject(topic, recordName string) string {
+	switch c.cfg.SubjectStrategy {
+	case "record_name":
+		return recordName
+	case "topic_record_name":
+		return fmt.Sprintf("%s-%s", topic, recordName)
+	default: // topic_name
+		return topic + "-value"
+	}
+}
+
+type schemaResponse struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+// GetByID fetches (and caches) the schema registered under the given ID
+func (c *Client) GetByID(ctx context.Context, id int) (*Schema, error) {
+	if s, ok := c.cachedSchema(id); ok {
+		return s, nil
+	}
+
+	url := fmt.Sprintf("%s/schemas/ids/%d", c.cfg.URL, id)
+	var resp schemaResponse
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+
+	schemaType := SchemaType(resp.SchemaType)
+	if schemaType == "" {
+		schemaType = SchemaTypeAvro // registry omits schemaType for AVRO (the default)
+	}
+
+	schema := &Schema{ID: id, Type: schemaType, Raw: resp.Schema}
+	c.storeSchema(id, schema)
+	return schema, nil
+}
+
+type registerRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type registerResponse struct {
+	ID int `json:"id"`
+}
+
+// Register registers (or looks up an existing) schema under a subject and
+// returns its ID, ready to be embedded in the Confluent wire-format prefix.
+func (c *Client) Register(ctx context.Context, subject string, schemaType SchemaType, rawSchema string) (int, error) {
+	url := fmt.Sprintf("%s/subjects/%s/versions", c.cfg.URL, subject)
+	var resp registerResponse
+	req := registerRequest{Schema: rawSchema, SchemaType: string(schemaType)}
+	if err := c.doJSON(ctx, http.MethodPost, url, req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to register schema for subject %s: %w", subject, err)
+	}
+
+	c.storeSchema(resp.ID, &Schema{ID: resp.ID, Type: schemaType, Raw: rawSchema, Subject: subject})
+	return resp.ID, nil
+}
+
+func (c *Client) cachedSchema(id int) (*Schema, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.cache[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.schema, true
+}
+
+func (c *Client) storeSchema(id int, schema *Schema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[id] = cacheEntry{schema: schema, expiresAt: time.Now().Add(c.cfg.CacheTTL)}
+}
+
+func (c *Client) doJSON(ctx context.Context, method, url string, body, out interface{}) error {
+	var bodyReader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(data)
+	} else {
+		bodyReader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("schema registry returned HTTP %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
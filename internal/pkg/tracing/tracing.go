@@ -5,6 +5,9 @@ import (
 	"io"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -15,13 +18,30 @@ import (
 
 var tracer trace.Tracer
 
-// Init initializes the OpenTelemetry tracer
-func Init(serviceName string, w io.Writer) (func(context.Context) error, error) {
-	// Create exporter (stdout for development, can be replaced with Jaeger/OTLP)
-	exporter, err := stdouttrace.New(
-		stdouttrace.WithWriter(w),
-		stdouttrace.WithPrettyPrint(),
-	)
+// Config selects the span exporter and sampling behavior used by Init.
+type Config struct {
+	// Exporter is one of "stdout", "otlp-grpc", "otlp-http" or "jaeger".
+	// "jaeger" is an alias for otlp-grpc, since Jaeger has ingested spans
+	// over OTLP natively since 1.35 and no longer needs its own exporter.
+	// Anything else falls back to "stdout".
+	Exporter string
+	// Endpoint is the collector address for otlp-grpc/otlp-http/jaeger,
+	// e.g. "localhost:4317" or "localhost:4318". Ignored for stdout.
+	Endpoint string
+	// Headers are attached to every export request, e.g. for collector auth.
+	Headers map[string]string
+	// SamplerRatio is the fraction of root spans (ones with no remote or
+	// local parent) that get sampled; spans with a sampled parent are
+	// always sampled. 0 disables tracing for root spans, 1 samples all.
+	SamplerRatio float64
+	// Insecure disables TLS when dialing the OTLP/Jaeger exporters.
+	Insecure bool
+}
+
+// Init initializes the OpenTelemetry tracer using cfg's exporter and
+// sampling settings, and returns the TracerProvider's shutdown func.
+func Init(serviceName string, w io.Writer, cfg Config) (func(context.Context) error, error) {
+	exporter, err := newExporter(context.Background(), w, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -40,7 +60,7 @@ func Init(serviceName string, w io.Writer) (func(context.Context) error, error)
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplerRatio))),
 	)
 
 	// Set global tracer provider
@@ -58,6 +78,33 @@ func Init(serviceName string, w io.Writer) (func(context.Context) error, error)
 	return tp.Shutdown, nil
 }
 
+// newExporter builds the span exporter selected by cfg.Exporter, defaulting
+// to stdout for local development when the value is empty or unrecognized.
+func newExporter(ctx context.Context, w io.Writer, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp-grpc", "jaeger":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case "otlp-http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+	}
+}
+
 // StartSpan starts a new span with the given name
 func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	if tracer == nil {
@@ -78,11 +125,21 @@ func RecordError(ctx context.Context, err error) {
 	span.RecordError(err)
 }
 
-// SetAttributes sets attributes on the current span
-func SetAttributes(ctx context.Context, attrs ...trace.SpanOption) {
-	// Note: This is a simplified version. In real usage, use trace.Span.SetAttributes
+// SetAttributes sets attributes on the span carried by ctx.
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
 	span := trace.SpanFromContext(ctx)
-	_ = span // Use the span for actual attribute setting
+	span.SetAttributes(attrs...)
+}
+
+// KafkaAttributes builds the messaging semantic-convention attributes for a
+// span that produces or consumes a message on the given Kafka topic.
+func KafkaAttributes(topic string, partition int, offset int64) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination", topic),
+		attribute.Int("messaging.kafka.partition", partition),
+		attribute.Int64("messaging.kafka.offset", offset),
+	}
 }
 
 // TraceID returns the trace ID from context
@@ -1,39 +1,45 @@
 package processor
 
 import (
+	"context"
 	"fmt"
-	"log"
 
-	"kafka-consumer/internal/models"
+	"kafka-consumer/internal/pkg/logger"
+	pb "kafka-consumer/proto"
 )
 
 // Processor defines the interface for processing image commands
 type Processor interface {
-	Process(cmd models.ImageCommand) error
+	// Process handles a single decoded command.
+	Process(ctx context.Context, cmd *pb.ImageCommand) error
+	// ProcessAny type-asserts cmd to *pb.ImageCommand before delegating to
+	// Process, so callers holding a decoder's interface{} result (e.g.
+	// queue.Handler) don't need to know the concrete command type.
+	ProcessAny(ctx context.Context, cmd interface{}) error
 }
 
 // StubProcessor is a stub implementation that logs commands for demonstration
 type StubProcessor struct {
-	ProcessedCommands []models.ImageCommand
+	ProcessedCommands []*pb.ImageCommand
 }
 
 // NewStubProcessor creates a new stub processor
 func NewStubProcessor() *StubProcessor {
 	return &StubProcessor{
-		ProcessedCommands: make([]models.ImageCommand, 0),
+		ProcessedCommands: make([]*pb.ImageCommand, 0),
 	}
 }
 
 // Process logs the command and stores it for later inspection
-func (p *StubProcessor) Process(cmd models.ImageCommand) error {
-	log.Printf("[PROCESSOR] Получена команда: ID=%s, Type=%s, ImageURL=%s",
-		cmd.ID, cmd.Command, cmd.ImageURL)
+func (p *StubProcessor) Process(ctx context.Context, cmd *pb.ImageCommand) error {
+	log := logger.FromContext(ctx).With("message_id", cmd.Id)
+	log.Info("Received command", "command", cmd.Command, "image_url", cmd.ImageUrl)
 
 	// Validate command
-	if cmd.ID == "" {
+	if cmd.Id == "" {
 		return fmt.Errorf("command ID is required")
 	}
-	if cmd.ImageURL == "" {
+	if cmd.ImageUrl == "" {
 		return fmt.Errorf("image URL is required")
 	}
 
@@ -42,31 +48,40 @@ func (p *StubProcessor) Process(cmd models.ImageCommand) error {
 
 	// Log processing based on command type
 	switch cmd.Command {
-	case models.CommandResize:
-		log.Printf("[PROCESSOR] Изменение размера изображения: %v", cmd.Parameters)
-	case models.CommandFilter:
-		log.Printf("[PROCESSOR] Применение фильтра к изображению: %v", cmd.Parameters)
-	case models.CommandTransform:
-		log.Printf("[PROCESSOR] Трансформация изображения: %v", cmd.Parameters)
-	case models.CommandAnalyze:
-		log.Printf("[PROCESSOR] Анализ изображения ИИ: %v", cmd.Parameters)
+	case pb.CommandType_COMMAND_TYPE_RESIZE:
+		log.Info("Resizing image", "parameters", cmd.Parameters)
+	case pb.CommandType_COMMAND_TYPE_FILTER:
+		log.Info("Applying filter to image", "parameters", cmd.Parameters)
+	case pb.CommandType_COMMAND_TYPE_TRANSFORM:
+		log.Info("Transforming image", "parameters", cmd.Parameters)
+	case pb.CommandType_COMMAND_TYPE_ANALYZE:
+		log.Info("Running AI analysis on image", "parameters", cmd.Parameters)
 	default:
-		log.Printf("[PROCESSOR] Неизвестная команда: %s", cmd.Command)
+		log.Warn("Unknown command type", "command", cmd.Command)
 	}
 
-	log.Printf("[PROCESSOR] Команда %s успешно обработана (заглушка)", cmd.ID)
+	log.Info("Command processed successfully (stub)")
 	return nil
 }
 
+// ProcessAny type-asserts cmd to *pb.ImageCommand before delegating to Process.
+func (p *StubProcessor) ProcessAny(ctx context.Context, cmd interface{}) error {
+	pbCmd, ok := cmd.(*pb.ImageCommand)
+	if !ok {
+		return fmt.Errorf("invalid command type: expected *pb.ImageCommand")
+	}
+	return p.Process(ctx, pbCmd)
+}
+
 // GetProcessedCount returns the number of processed commands
 func (p *StubProcessor) GetProcessedCount() int {
 	return len(p.ProcessedCommands)
 }
 
 // GetLastCommand returns the last processed command
-func (p *StubProcessor) GetLastCommand() *models.ImageCommand {
+func (p *StubProcessor) GetLastCommand() *pb.ImageCommand {
 	if len(p.ProcessedCommands) == 0 {
 		return nil
 	}
-	return &p.ProcessedCommands[len(p.ProcessedCommands)-1]
+	return p.ProcessedCommands[len(p.ProcessedCommands)-1]
 }
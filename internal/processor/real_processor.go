@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/puller"
 	"kafka-consumer/internal/usecase"
 	pb "kafka-consumer/proto"
 )
@@ -13,11 +15,15 @@ type RealProcessor struct {
 	imageProcessor usecase.ImageProcessor
 }
 
-// NewRealProcessor creates a new real processor wrapping the image processor
-func NewRealProcessor(outputDir string) *RealProcessor {
+// NewRealProcessor creates a new real processor wrapping the image processor.
+// p is the puller used to fetch image sources referenced by command URIs.
+// pipelineMaxSteps caps how many steps a COMMAND_TYPE_PIPELINE command may
+// chain; values <= 0 fall back to usecase's default. rec may be nil, in
+// which case the image processor records through the default global metrics.
+func NewRealProcessor(outputDir string, p puller.Puller, pipelineMaxSteps int, rec metrics.Recorder) (*RealProcessor, error) {
 	return &RealProcessor{
-		imageProcessor: usecase.NewRealImageProcessor(outputDir),
-	}
+		imageProcessor: usecase.NewRealImageProcessor(outputDir, p, pipelineMaxSteps, rec),
+	}, nil
 }
 
 // ProcessAny handles interface{} type from decoder
@@ -0,0 +1,108 @@
+package puller
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestCache(t *testing.T, maxBytes int64) *DiskCache {
+	t.Helper()
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, maxBytes)
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+	return cache
+}
+
+func TestDiskCache_PutAndOpen_ThenGet(t *testing.T) {
+	cache := newTestCache(t, 0)
+
+	rc, err := cache.PutAndOpen("uri-1", io.NopCloser(strings.NewReader("hello")))
+	if err != nil {
+		t.Fatalf("PutAndOpen returned error: %v", err)
+	}
+	assertReads(t, rc, "hello")
+
+	rc, ok := cache.Get("uri-1")
+	if !ok {
+		t.Fatal("expected a cache hit after PutAndOpen")
+	}
+	assertReads(t, rc, "hello")
+}
+
+func TestDiskCache_Get_MissForUnknownKey(t *testing.T) {
+	cache := newTestCache(t, 0)
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("expected a cache miss for a key never put")
+	}
+}
+
+func TestDiskCache_DifferentKeysAreIndependent(t *testing.T) {
+	cache := newTestCache(t, 0)
+
+	if _, err := cache.PutAndOpen("uri-1#etag-a", io.NopCloser(strings.NewReader("a"))); err != nil {
+		t.Fatalf("PutAndOpen returned error: %v", err)
+	}
+	if _, err := cache.PutAndOpen("uri-1#etag-b", io.NopCloser(strings.NewReader("b"))); err != nil {
+		t.Fatalf("PutAndOpen returned error: %v", err)
+	}
+
+	rcA, ok := cache.Get("uri-1#etag-a")
+	if !ok {
+		t.Fatal("expected uri-1#etag-a to still be cached")
+	}
+	assertReads(t, rcA, "a")
+
+	rcB, ok := cache.Get("uri-1#etag-b")
+	if !ok {
+		t.Fatal("expected uri-1#etag-b to still be cached")
+	}
+	assertReads(t, rcB, "b")
+}
+
+func TestDiskCache_EvictsOldestWhenOverBudget(t *testing.T) {
+	cache := newTestCache(t, 5)
+
+	if _, err := cache.PutAndOpen("first", io.NopCloser(strings.NewReader("aaaaa"))); err != nil {
+		t.Fatalf("PutAndOpen returned error: %v", err)
+	}
+	if _, err := cache.PutAndOpen("second", io.NopCloser(strings.NewReader("bbbbb"))); err != nil {
+		t.Fatalf("PutAndOpen returned error: %v", err)
+	}
+
+	if _, ok := cache.Get("first"); ok {
+		t.Error("expected the oldest entry to be evicted once the byte budget is exceeded")
+	}
+	if _, ok := cache.Get("second"); !ok {
+		t.Error("expected the most recently inserted entry to still be cached")
+	}
+}
+
+func TestDiskCache_Invalidate_DropsEntry(t *testing.T) {
+	cache := newTestCache(t, 0)
+
+	if _, err := cache.PutAndOpen("uri-1", io.NopCloser(strings.NewReader("hello"))); err != nil {
+		t.Fatalf("PutAndOpen returned error: %v", err)
+	}
+
+	cache.Invalidate("uri-1")
+
+	if _, ok := cache.Get("uri-1"); ok {
+		t.Error("expected Get to miss after Invalidate")
+	}
+}
+
+func assertReads(t *testing.T, rc io.ReadCloser, want string) {
+	t.Helper()
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read cached content: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("cached content = %q, want %q", got, want)
+	}
+}
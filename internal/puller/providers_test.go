@@ -0,0 +1,141 @@
+package puller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPProvider_Pull_RetriesThenSucceeds(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("image-bytes"))
+	}))
+	defer server.Close()
+
+	p := NewHTTPProvider("http", nil)
+	rc, err := p.Pull(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("expected Pull to succeed after retries, got %v", err)
+	}
+	assertReads(t, rc, "image-bytes")
+	if attempts != 3 {
+		t.Errorf("expected 3 GET attempts (2 failures + 1 success), got %d", attempts)
+	}
+}
+
+func TestHTTPProvider_Pull_ExhaustsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p := NewHTTPProvider("http", nil)
+	if _, err := p.Pull(context.Background(), server.URL); err == nil {
+		t.Fatal("expected an error once all attempts are exhausted")
+	}
+}
+
+func TestHTTPProvider_Pull_ChunkedRangeRequestsReassembleInOrder(t *testing.T) {
+	body := strings.Repeat("a", 10) + strings.Repeat("b", 10) + strings.Repeat("c", 5)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", "25")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			w.Write([]byte(body))
+			return
+		}
+		start, end, err := parseRange(rangeHeader)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if end >= len(body) {
+			end = len(body) - 1
+		}
+		w.Write([]byte(body[start : end+1]))
+	}))
+	defer server.Close()
+
+	p := NewHTTPProvider("http", nil)
+	p.chunkSize = 10
+	p.chunkConcurrency = 2
+
+	rc, err := p.Pull(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	assertReads(t, rc, body)
+}
+
+func TestHTTPProvider_ETag_ReturnsHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc123"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewHTTPProvider("http", nil)
+	etag, err := p.ETag(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ETag returned error: %v", err)
+	}
+	if etag != `"abc123"` {
+		t.Errorf("ETag = %q, want %q", etag, `"abc123"`)
+	}
+}
+
+func TestFileProvider_Pull_ReadsLocalFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.bin")
+	if err := os.WriteFile(path, []byte("local-bytes"), 0644); err != nil {
+		t.Fatalf("failed to write fixture file: %v", err)
+	}
+
+	p := NewFileProvider()
+	rc, err := p.Pull(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	assertReads(t, rc, "local-bytes")
+}
+
+func TestFileProvider_Pull_MissingFileErrors(t *testing.T) {
+	p := NewFileProvider()
+	if _, err := p.Pull(context.Background(), filepath.Join(t.TempDir(), "missing.bin")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+// parseRange parses a "bytes=start-end" Range header into start and end.
+func parseRange(header string) (start, end int, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(header, "bytes="), "-", 2)
+	if start, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	end, err = strconv.Atoi(parts[1])
+	return start, end, err
+}
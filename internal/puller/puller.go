@@ -0,0 +1,110 @@
+// Package puller fetches image bytes from a variety of source URIs
+// (s3://, http(s)://, file://, minio://), dispatching to a per-scheme
+// Provider and caching results on disk so repeated commands against the
+// same source don't re-download. Adding gs:// support means adding a
+// Provider for it (see providers.go) and registering it in
+// app.newImagePuller; no provider exists for that scheme yet.
+package puller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"kafka-consumer/internal/pkg/metrics"
+)
+
+// Provider fetches the bytes behind a single URI scheme
+type Provider interface {
+	// Scheme returns the URI scheme this provider handles, e.g. "s3"
+	Scheme() string
+	// Pull opens a reader for the given URI
+	Pull(ctx context.Context, uri string) (io.ReadCloser, error)
+}
+
+// ETagProvider is implemented by Providers that can report a cache
+// validation token for a URI without fetching its full body. When a
+// Provider implements it, MultiProviderPuller folds the ETag into the
+// cache key so a source that changes upstream gets re-pulled instead of
+// serving stale cached bytes indefinitely. Providers that don't implement
+// it (or that return "") are cached by URI alone.
+type ETagProvider interface {
+	ETag(ctx context.Context, uri string) (string, error)
+}
+
+// Puller resolves a URI to its scheme's Provider and fans out through the
+// on-disk cache.
+type Puller interface {
+	Pull(ctx context.Context, uri string) (io.ReadCloser, error)
+}
+
+// MultiProviderPuller dispatches to registered Provider implementations by
+// URI scheme, with an on-disk LRU cache in front.
+type MultiProviderPuller struct {
+	providers map[string]Provider
+	cache     *DiskCache
+}
+
+// New creates a puller with the given providers registered by scheme, and an
+// optional disk cache (nil disables caching).
+func New(cache *DiskCache, providers ...Provider) *MultiProviderPuller {
+	p := &MultiProviderPuller{
+		providers: make(map[string]Provider, len(providers)),
+		cache:     cache,
+	}
+	for _, provider := range providers {
+		p.providers[provider.Scheme()] = provider
+	}
+	return p
+}
+
+// Pull fetches the content at uri, serving from cache when possible
+func (p *MultiProviderPuller) Pull(ctx context.Context, uri string) (io.ReadCloser, error) {
+	scheme := schemeOf(uri)
+
+	provider, ok := p.providers[scheme]
+	if !ok {
+		return nil, fmt.Errorf("no puller provider registered for scheme %q", scheme)
+	}
+
+	cacheKey := uri
+	if etagProvider, ok := provider.(ETagProvider); ok {
+		if etag, err := etagProvider.ETag(ctx, uri); err == nil && etag != "" {
+			cacheKey = uri + "#" + etag
+		}
+	}
+
+	if p.cache != nil {
+		if rc, ok := p.cache.Get(cacheKey); ok {
+			metrics.RecordPullerCacheResult("hit")
+			return rc, nil
+		}
+		metrics.RecordPullerCacheResult("miss")
+	}
+
+	rc, err := provider.Pull(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", uri, err)
+	}
+
+	if p.cache != nil {
+		return p.cache.PutAndOpen(cacheKey, rc)
+	}
+	return rc, nil
+}
+
+// schemeOf extracts the URI scheme, defaulting to "file" for bare paths
+// (including Windows-style paths) and for URIs that fail to parse or carry
+// no scheme.
+func schemeOf(uri string) string {
+	if idx := strings.Index(uri, "://"); idx == -1 {
+		return "file"
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return "file"
+	}
+	return u.Scheme
+}
@@ -0,0 +1,137 @@
+package puller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type fakeProvider struct {
+	scheme string
+	body   string
+	etag   string
+	calls  int
+	err    error
+}
+
+func (p *fakeProvider) Scheme() string { return p.scheme }
+
+func (p *fakeProvider) Pull(ctx context.Context, uri string) (io.ReadCloser, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return io.NopCloser(strings.NewReader(p.body)), nil
+}
+
+type fakeETagProvider struct {
+	fakeProvider
+}
+
+func (p *fakeETagProvider) ETag(ctx context.Context, uri string) (string, error) {
+	return p.etag, nil
+}
+
+func TestMultiProviderPuller_Pull_UnknownSchemeErrors(t *testing.T) {
+	p := New(nil, &fakeProvider{scheme: "s3", body: "data"})
+
+	if _, err := p.Pull(context.Background(), "gs://bucket/key"); err == nil {
+		t.Error("expected an error for a scheme with no registered provider")
+	}
+}
+
+func TestMultiProviderPuller_Pull_NoCache_AlwaysCallsProvider(t *testing.T) {
+	provider := &fakeProvider{scheme: "file", body: "hello"}
+	p := New(nil, provider)
+
+	for i := 0; i < 2; i++ {
+		rc, err := p.Pull(context.Background(), "file:///tmp/x")
+		if err != nil {
+			t.Fatalf("Pull returned error: %v", err)
+		}
+		assertReads(t, rc, "hello")
+	}
+	if provider.calls != 2 {
+		t.Errorf("expected every Pull to hit the provider without a cache, got %d calls", provider.calls)
+	}
+}
+
+func TestMultiProviderPuller_Pull_CachesAcrossCalls(t *testing.T) {
+	provider := &fakeProvider{scheme: "file", body: "hello"}
+	cache := newTestCache(t, 0)
+	p := New(cache, provider)
+
+	for i := 0; i < 3; i++ {
+		rc, err := p.Pull(context.Background(), "file:///tmp/x")
+		if err != nil {
+			t.Fatalf("Pull returned error: %v", err)
+		}
+		assertReads(t, rc, "hello")
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected the provider to be called once and served from cache after, got %d calls", provider.calls)
+	}
+}
+
+func TestMultiProviderPuller_Pull_ETagProviderRefetchesOnChange(t *testing.T) {
+	provider := &fakeETagProvider{fakeProvider{scheme: "http", body: "v1", etag: "etag-1"}}
+	cache := newTestCache(t, 0)
+	p := New(cache, provider)
+
+	rc, err := p.Pull(context.Background(), "http://example.com/img")
+	if err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	assertReads(t, rc, "v1")
+
+	// A repeat pull with the same ETag should be served from cache.
+	rc, err = p.Pull(context.Background(), "http://example.com/img")
+	if err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	assertReads(t, rc, "v1")
+	if provider.calls != 1 {
+		t.Fatalf("expected a cache hit for an unchanged ETag, got %d provider calls", provider.calls)
+	}
+
+	// The upstream source changes: a new ETag should produce a fresh fetch.
+	provider.body = "v2"
+	provider.etag = "etag-2"
+	rc, err = p.Pull(context.Background(), "http://example.com/img")
+	if err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	assertReads(t, rc, "v2")
+	if provider.calls != 2 {
+		t.Errorf("expected a changed ETag to trigger a re-fetch, got %d provider calls", provider.calls)
+	}
+}
+
+func TestMultiProviderPuller_Pull_ProviderErrorWrapsURI(t *testing.T) {
+	provider := &fakeProvider{scheme: "file", err: errors.New("boom")}
+	p := New(nil, provider)
+
+	_, err := p.Pull(context.Background(), "file:///tmp/x")
+	if err == nil {
+		t.Fatal("expected an error when the provider fails")
+	}
+}
+
+func TestSchemeOf(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"s3://bucket/key", "s3"},
+		{"https://example.com/img.jpg", "https"},
+		{"/local/path", "file"},
+		{"C:\\local\\path", "file"},
+	}
+	for _, tt := range tests {
+		if got := schemeOf(tt.uri); got != tt.want {
+			t.Errorf("schemeOf(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
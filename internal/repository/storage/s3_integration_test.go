@@ -0,0 +1,96 @@
+//go:build integration
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/minio"
+)
+
+// TestS3Storage_MinIO exercises S3Storage against a real MinIO instance started
+// via testcontainers. Run with `go test -tags=integration ./...`.
+func TestS3Storage_MinIO(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := minio.Run(ctx, "minio/minio:RELEASE.2024-01-16T16-07-38Z")
+	if err != nil {
+		t.Fatalf("failed to start minio container: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			t.Logf("failed to terminate container: %v", err)
+		}
+	})
+
+	endpoint, err := container.ConnectionString(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection string: %v", err)
+	}
+
+	store, err := NewS3Storage(ctx, S3Config{
+		Bucket:          "kafka-consumer-it",
+		Region:          "us-east-1",
+		Endpoint:        endpoint,
+		AccessKeyID:     container.Username,
+		SecretAccessKey: container.Password,
+		UseSSL:          false,
+	})
+	if err != nil {
+		t.Fatalf("failed to create S3 storage: %v", err)
+	}
+
+	const key = "results/test-image.png"
+	payload := []byte("fake-png-bytes")
+
+	if _, err := store.Upload(ctx, key, bytes.NewReader(payload)); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	exists, err := store.Exists(ctx, key)
+	if err != nil {
+		t.Fatalf("Exists failed: %v", err)
+	}
+	if !exists {
+		t.Fatal("expected object to exist after upload")
+	}
+
+	rc, err := store.Download(ctx, key)
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read downloaded object: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("expected %q, got %q", payload, got)
+	}
+
+	url, err := store.PresignedURL(ctx, key, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignedURL failed: %v", err)
+	}
+	if url == "" {
+		t.Error("expected non-empty presigned URL")
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	exists, err = store.Exists(ctx, key)
+	if err != nil {
+		t.Fatalf("Exists after delete failed: %v", err)
+	}
+	if exists {
+		t.Error("expected object to no longer exist after delete")
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 
 	"kafka-consumer/internal/pkg/logger"
 )
@@ -15,6 +20,8 @@ type Storage interface {
 	Download(ctx context.Context, key string) (io.ReadCloser, error)
 	Upload(ctx context.Context, key string, data io.Reader) (string, error)
 	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
 }
 
 // LocalStorage implements Storage interface using local filesystem
@@ -36,7 +43,7 @@ func (s *LocalStorage) Download(ctx context.Context, key string) (io.ReadCloser,
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
 	}
-	logger.Debug("Downloaded file from local storage", "key", key, "path", path)
+	logger.FromContext(ctx).Debug("Downloaded file from local storage", "key", key, "path", path)
 	return file, nil
 }
 
@@ -60,7 +67,7 @@ func (s *LocalStorage) Upload(ctx context.Context, key string, data io.Reader) (
 		return "", fmt.Errorf("failed to write file %s: %w", path, err)
 	}
 
-	logger.Debug("Uploaded file to local storage", "key", key, "path", path)
+	logger.FromContext(ctx).Debug("Uploaded file to local storage", "key", key, "path", path)
 	return path, nil
 }
 
@@ -70,45 +77,149 @@ func (s *LocalStorage) Delete(ctx context.Context, key string) error {
 	if err := os.Remove(path); err != nil {
 		return fmt.Errorf("failed to delete file %s: %w", path, err)
 	}
-	logger.Debug("Deleted file from local storage", "key", key, "path", path)
+	logger.FromContext(ctx).Debug("Deleted file from local storage", "key", key, "path", path)
 	return nil
 }
 
-// S3Storage implements Storage interface using AWS S3
-// This is a placeholder for real S3 implementation
+// Exists reports whether a file is present in local storage
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	path := filepath.Join(s.basePath, key)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// PresignedURL returns a file:// URL for local storage
+// There is no real signing for local files; the expiry is accepted for interface parity.
+func (s *LocalStorage) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "file://" + filepath.Join(s.basePath, key), nil
+}
+
+// S3Config holds the settings needed to reach an S3-compatible endpoint
+type S3Config struct {
+	Bucket            string
+	Region            string
+	Endpoint          string
+	AccessKeyID       string
+	SecretAccessKey   string
+	UseSSL            bool
+	ServerSideEncrypt bool
+	PartSize          uint64
+	UploadConcurrency int
+}
+
+// S3Storage implements Storage interface using an S3-compatible backend via minio-go
 type S3Storage struct {
-	bucket   string
-	region   string
-	endpoint string
+	client *minio.Client
+	bucket string
+	cfg    S3Config
 }
 
-// NewS3Storage creates a new S3 storage instance
-func NewS3Storage(bucket, region, endpoint string) *S3Storage {
-	return &S3Storage{
-		bucket:   bucket,
-		region:   region,
-		endpoint: endpoint,
+// NewS3Storage creates a new S3 storage instance and ensures the target bucket exists
+func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create minio client: %w", err)
 	}
+
+	if cfg.PartSize == 0 {
+		cfg.PartSize = 64 * 1024 * 1024 // 64MB parts by default
+	}
+	if cfg.UploadConcurrency == 0 {
+		cfg.UploadConcurrency = 4
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", cfg.Bucket, err)
+		}
+		logger.FromContext(ctx).Info("Created S3 bucket", "bucket", cfg.Bucket)
+	}
+
+	return &S3Storage{
+		client: client,
+		bucket: cfg.Bucket,
+		cfg:    cfg,
+	}, nil
 }
 
-// Download downloads a file from S3
+// Download streams an object from S3
 func (s *S3Storage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
-	// TODO: Implement real S3 download using aws-sdk-go-v2
-	// For now, return an error indicating not implemented
-	logger.Warn("S3 download not implemented, use LOCAL_STORAGE=true for development")
-	return nil, fmt.Errorf("S3 storage not implemented: bucket=%s, key=%s", s.bucket, key)
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	// GetObject does not fail until the first read, so confirm the object exists now
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("failed to stat object %s: %w", key, err)
+	}
+
+	logger.FromContext(ctx).Debug("Downloaded object from S3", "bucket", s.bucket, "key", key)
+	return obj, nil
 }
 
-// Upload uploads a file to S3
+// Upload performs a multipart-capable upload of data to S3
 func (s *S3Storage) Upload(ctx context.Context, key string, data io.Reader) (string, error) {
-	// TODO: Implement real S3 upload using aws-sdk-go-v2
-	logger.Warn("S3 upload not implemented, use LOCAL_STORAGE=true for development")
-	return "", fmt.Errorf("S3 storage not implemented: bucket=%s, key=%s", s.bucket, key)
+	opts := minio.PutObjectOptions{
+		PartSize:    s.cfg.PartSize,
+		NumThreads:  uint(s.cfg.UploadConcurrency),
+		ContentType: "application/octet-stream",
+	}
+	if s.cfg.ServerSideEncrypt {
+		opts.ServerSideEncryption = encrypt.NewSSE()
+	}
+
+	// Size -1 tells minio-go to stream and multipart-upload as needed
+	info, err := s.client.PutObject(ctx, s.bucket, key, data, -1, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+
+	logger.FromContext(ctx).Debug("Uploaded object to S3", "bucket", s.bucket, "key", key, "size", info.Size)
+	return fmt.Sprintf("s3://%s/%s", s.bucket, key), nil
 }
 
-// Delete deletes a file from S3
+// Delete removes an object from S3
 func (s *S3Storage) Delete(ctx context.Context, key string) error {
-	// TODO: Implement real S3 delete using aws-sdk-go-v2
-	logger.Warn("S3 delete not implemented, use LOCAL_STORAGE=true for development")
-	return fmt.Errorf("S3 storage not implemented: bucket=%s, key=%s", s.bucket, key)
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	logger.FromContext(ctx).Debug("Deleted object from S3", "bucket", s.bucket, "key", key)
+	return nil
+}
+
+// Exists checks for object presence using a HeadObject call
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		errResp := minio.ToErrorResponse(err)
+		if errResp.Code == "NoSuchKey" || errResp.Code == "NotFound" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// PresignedURL generates a time-limited URL for downloading an object result
+func (s *S3Storage) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", key, err)
+	}
+	return u.String(), nil
 }
@@ -1,11 +1,11 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"image"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"time"
@@ -14,6 +14,7 @@ import (
 
 	"kafka-consumer/internal/pkg/logger"
 	"kafka-consumer/internal/pkg/metrics"
+	"kafka-consumer/internal/puller"
 	pb "kafka-consumer/proto"
 )
 
@@ -31,17 +32,40 @@ type ProcessingResult struct {
 	ProcessingTimeMs int64
 }
 
+// defaultPipelineMaxSteps bounds a COMMAND_TYPE_PIPELINE command's step
+// count when NewRealImageProcessor is given maxSteps <= 0.
+const defaultPipelineMaxSteps = 10
+
 // RealImageProcessor implements actual image processing
 type RealImageProcessor struct {
-	outputDir string
+	outputDir        string
+	puller           puller.Puller
+	pipelineMaxSteps int
+	recorder         metrics.Recorder
 }
 
-// NewRealImageProcessor creates a new real image processor
-func NewRealImageProcessor(outputDir string) *RealImageProcessor {
+// NewRealImageProcessor creates a new real image processor. Image sources are
+// fetched through p, which dispatches by URI scheme (s3://, http(s)://, etc.)
+// and caches results on disk. pipelineMaxSteps caps how many steps a
+// COMMAND_TYPE_PIPELINE command may chain; values <= 0 fall back to
+// defaultPipelineMaxSteps. rec may be nil, in which case metrics are recorded
+// through the package-level collectors via metrics.DefaultRecorder; tests can
+// pass a fake Recorder to assert on observations without touching global
+// Prometheus state.
+func NewRealImageProcessor(outputDir string, p puller.Puller, pipelineMaxSteps int, rec metrics.Recorder) *RealImageProcessor {
 	// Create output directory if it doesn't exist
 	os.MkdirAll(outputDir, 0755)
+	if pipelineMaxSteps <= 0 {
+		pipelineMaxSteps = defaultPipelineMaxSteps
+	}
+	if rec == nil {
+		rec = metrics.DefaultRecorder{}
+	}
 	return &RealImageProcessor{
-		outputDir: outputDir,
+		outputDir:        outputDir,
+		puller:           p,
+		pipelineMaxSteps: pipelineMaxSteps,
+		recorder:         rec,
 	}
 }
 
@@ -50,7 +74,7 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 	start := time.Now()
 	commandType := cmd.Command.String()
 
-	log := logger.With(
+	log := logger.FromContext(ctx).With(
 		"command_id", cmd.Id,
 		"command_type", commandType,
 		"image_url", cmd.ImageUrl,
@@ -64,25 +88,25 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 
 	// Validate command
 	if cmd.Id == "" {
-		metrics.RecordMessageProcessed(commandType, "error")
+		p.recorder.RecordMessageProcessed(commandType, "error")
 		return nil, fmt.Errorf("command ID is required")
 	}
-	if cmd.ImageUrl == "" {
-		metrics.RecordMessageProcessed(commandType, "error")
-		return nil, fmt.Errorf("image URL is required")
+	if cmd.ImageUrl == "" && len(cmd.GetInlineImage()) == 0 {
+		p.recorder.RecordMessageProcessed(commandType, "error")
+		return nil, fmt.Errorf("image URL or inline image is required")
 	}
 
-	// Download image
-	img, format, err := p.downloadImage(ctx, cmd.ImageUrl)
+	// Load the source image, preferring inline bytes over a URL fetch
+	img, format, err := p.loadImage(ctx, cmd)
 	if err != nil {
-		log.Error("Failed to download image", "error", err)
+		log.Error("Failed to load image", "error", err)
 		result.Success = false
-		result.ErrorMessage = fmt.Sprintf("download failed: %v", err)
-		metrics.RecordMessageProcessed(commandType, "error")
+		result.ErrorMessage = fmt.Sprintf("load failed: %v", err)
+		p.recorder.RecordMessageProcessed(commandType, "error")
 		return result, err
 	}
 
-	log.Info("Image downloaded", "format", format, "width", img.Bounds().Dx(), "height", img.Bounds().Dy())
+	log.Info("Image loaded", "format", format, "width", img.Bounds().Dx(), "height", img.Bounds().Dy())
 
 	// Process based on command type
 	var processedImg image.Image
@@ -95,13 +119,15 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 		processedImg, err = p.processTransform(img, cmd.GetTransform())
 	case pb.CommandType_COMMAND_TYPE_CROP:
 		processedImg, err = p.processCrop(img, cmd.GetCrop())
+	case pb.CommandType_COMMAND_TYPE_PIPELINE:
+		processedImg, err = p.processPipeline(ctx, img, cmd.GetPipeline())
 	case pb.CommandType_COMMAND_TYPE_ANALYZE:
 		// For analyze, we just return image info
 		result.Success = true
 		result.OutputPath = ""
 		result.ProcessingTimeMs = time.Since(start).Milliseconds()
 		log.Info("Image analyzed", "width", img.Bounds().Dx(), "height", img.Bounds().Dy())
-		metrics.RecordMessageProcessed(commandType, "success")
+		p.recorder.RecordMessageProcessed(commandType, "success")
 		return result, nil
 	case pb.CommandType_COMMAND_TYPE_REMOVE_BACKGROUND:
 		// Placeholder for AI background removal
@@ -115,7 +141,7 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 		log.Error("Processing failed", "error", err)
 		result.Success = false
 		result.ErrorMessage = err.Error()
-		metrics.RecordMessageProcessed(commandType, "error")
+		p.recorder.RecordMessageProcessed(commandType, "error")
 		return result, err
 	}
 
@@ -125,7 +151,7 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 		log.Error("Failed to save processed image", "error", err)
 		result.Success = false
 		result.ErrorMessage = fmt.Sprintf("save failed: %v", err)
-		metrics.RecordMessageProcessed(commandType, "error")
+		p.recorder.RecordMessageProcessed(commandType, "error")
 		return result, err
 	}
 
@@ -134,8 +160,8 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 	result.OutputPath = outputPath
 	result.ProcessingTimeMs = duration.Milliseconds()
 
-	metrics.ObserveMessageProcessingDuration(commandType, duration.Seconds())
-	metrics.RecordMessageProcessed(commandType, "success")
+	p.recorder.ObserveMessageProcessingDuration(commandType, duration.Seconds())
+	p.recorder.RecordMessageProcessed(commandType, "success")
 
 	log.Info("Image processed successfully",
 		"output_path", outputPath,
@@ -144,26 +170,34 @@ func (p *RealImageProcessor) Process(ctx context.Context, cmd *pb.ImageCommand)
 	return result, nil
 }
 
-// downloadImage downloads an image from URL
-func (p *RealImageProcessor) downloadImage(ctx context.Context, url string) (image.Image, string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, "", err
+// loadImage resolves cmd's source image, preferring InlineImage bytes (set
+// by producers that embed small images directly rather than publish a URL)
+// over fetching ImageUrl through the puller.
+func (p *RealImageProcessor) loadImage(ctx context.Context, cmd *pb.ImageCommand) (image.Image, string, error) {
+	if inline := cmd.GetInlineImage(); len(inline) > 0 {
+		img, format, err := image.Decode(bytes.NewReader(inline))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode inline image: %w", err)
+		}
+		if format == "" {
+			format = cmd.GetInlineFormat()
+		}
+		return img, format, nil
 	}
+	return p.downloadImage(ctx, cmd.ImageUrl)
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+// downloadImage fetches the image source through the puller, which dispatches
+// by URI scheme and may serve the bytes from its on-disk cache
+func (p *RealImageProcessor) downloadImage(ctx context.Context, uri string) (image.Image, string, error) {
+	rc, err := p.puller.Pull(ctx, uri)
 	if err != nil {
 		return nil, "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
+	defer rc.Close()
 
 	// Limit read to 50MB
-	limitedReader := io.LimitReader(resp.Body, 50*1024*1024)
+	limitedReader := io.LimitReader(rc, 50*1024*1024)
 
 	img, format, err := image.Decode(limitedReader)
 	if err != nil {
@@ -287,3 +321,69 @@ func (p *RealImageProcessor) processCrop(img image.Image, params *pb.CropParamet
 	rect := image.Rect(x, y, x+width, y+height)
 	return imaging.Crop(img, rect), nil
 }
+
+// processPipeline folds img through params.Steps left to right, reusing the
+// same per-command helpers as a standalone RESIZE/FILTER/TRANSFORM/CROP
+// command. An ANALYZE step is a no-op on the image, logged for visibility.
+// Each step's duration is recorded under kafka_consumer_pipeline_step_duration_seconds.
+// On failure the returned error identifies which step failed, e.g.
+// "step[2] filter: unknown filter type: xyz".
+func (p *RealImageProcessor) processPipeline(ctx context.Context, img image.Image, params *pb.PipelineParameters) (image.Image, error) {
+	if params == nil || len(params.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline requires at least one step")
+	}
+	if len(params.Steps) > p.pipelineMaxSteps {
+		return nil, fmt.Errorf("pipeline has %d steps, exceeding the maximum of %d", len(params.Steps), p.pipelineMaxSteps)
+	}
+
+	log := logger.FromContext(ctx)
+	result := img
+
+	for i, step := range params.Steps {
+		name := pipelineStepName(step.Command)
+		stepStart := time.Now()
+
+		var err error
+		switch step.Command {
+		case pb.CommandType_COMMAND_TYPE_RESIZE:
+			result, err = p.processResize(result, step.GetResize())
+		case pb.CommandType_COMMAND_TYPE_FILTER:
+			result, err = p.processFilter(result, step.GetFilter())
+		case pb.CommandType_COMMAND_TYPE_TRANSFORM:
+			result, err = p.processTransform(result, step.GetTransform())
+		case pb.CommandType_COMMAND_TYPE_CROP:
+			result, err = p.processCrop(result, step.GetCrop())
+		case pb.CommandType_COMMAND_TYPE_ANALYZE:
+			log.Info("Pipeline analyze step", "step", i, "width", result.Bounds().Dx(), "height", result.Bounds().Dy())
+		default:
+			err = fmt.Errorf("unsupported pipeline step type: %s", step.Command)
+		}
+
+		p.recorder.ObservePipelineStepDuration(name, time.Since(stepStart).Seconds())
+
+		if err != nil {
+			return nil, fmt.Errorf("step[%d] %s: %w", i, name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// pipelineStepName maps a step's command type to the short label used in
+// metrics and error messages.
+func pipelineStepName(ct pb.CommandType) string {
+	switch ct {
+	case pb.CommandType_COMMAND_TYPE_RESIZE:
+		return "resize"
+	case pb.CommandType_COMMAND_TYPE_FILTER:
+		return "filter"
+	case pb.CommandType_COMMAND_TYPE_TRANSFORM:
+		return "transform"
+	case pb.CommandType_COMMAND_TYPE_CROP:
+		return "crop"
+	case pb.CommandType_COMMAND_TYPE_ANALYZE:
+		return "analyze"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kafka-consumer/internal/puller"
+	pb "kafka-consumer/proto"
+)
+
+// fakeRecorder is a metrics.Recorder that records call arguments instead of
+// touching the global Prometheus collectors, so tests can assert on
+// observations directly.
+type fakeRecorder struct {
+	processed         []string
+	durations         []string
+	pipelineStepNames []string
+}
+
+func (f *fakeRecorder) RecordMessageProcessed(command, status string) {
+	f.processed = append(f.processed, command+":"+status)
+}
+
+func (f *fakeRecorder) ObserveMessageProcessingDuration(command string, duration float64) {
+	f.durations = append(f.durations, command)
+}
+
+func (f *fakeRecorder) ObservePipelineStepDuration(step string, duration float64) {
+	f.pipelineStepNames = append(f.pipelineStepNames, step)
+}
+
+// writeTestPNG creates a small fixture image on disk and returns its path.
+func writeTestPNG(t *testing.T) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "source.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create source image: %v", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("encode source image: %v", err)
+	}
+	return path
+}
+
+func TestRealImageProcessor_Process_Pipeline(t *testing.T) {
+	outputDir := t.TempDir()
+	sourcePath := writeTestPNG(t)
+
+	p := NewRealImageProcessor(outputDir, puller.New(nil, puller.NewFileProvider()), 0, nil)
+
+	cmd := &pb.ImageCommand{
+		Id:       "pipeline-1",
+		ImageUrl: sourcePath,
+		Command:  pb.CommandType_COMMAND_TYPE_PIPELINE,
+		Parameters: &pb.ImageCommand_Pipeline{
+			Pipeline: &pb.PipelineParameters{
+				Steps: []*pb.PipelineStep{
+					{
+						Command: pb.CommandType_COMMAND_TYPE_RESIZE,
+						Parameters: &pb.PipelineStep_Resize{
+							Resize: &pb.ResizeParameters{Width: 10, Height: 10},
+						},
+					},
+					{
+						Command: pb.CommandType_COMMAND_TYPE_FILTER,
+						Parameters: &pb.PipelineStep_Filter{
+							Filter: &pb.FilterParameters{FilterType: "grayscale"},
+						},
+					},
+					{
+						Command: pb.CommandType_COMMAND_TYPE_CROP,
+						Parameters: &pb.PipelineStep_Crop{
+							Crop: &pb.CropParameters{Width: 5, Height: 5},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := p.Process(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %s", result.ErrorMessage)
+	}
+	if _, err := os.Stat(result.OutputPath); err != nil {
+		t.Errorf("expected processed output at %s: %v", result.OutputPath, err)
+	}
+}
+
+func TestRealImageProcessor_Process_Pipeline_Empty(t *testing.T) {
+	p := NewRealImageProcessor(t.TempDir(), puller.New(nil, puller.NewFileProvider()), 0, nil)
+
+	cmd := &pb.ImageCommand{
+		Id:       "pipeline-empty",
+		ImageUrl: writeTestPNG(t),
+		Command:  pb.CommandType_COMMAND_TYPE_PIPELINE,
+		Parameters: &pb.ImageCommand_Pipeline{
+			Pipeline: &pb.PipelineParameters{},
+		},
+	}
+
+	if _, err := p.Process(context.Background(), cmd); err == nil {
+		t.Fatal("expected error for an empty pipeline, got nil")
+	}
+}
+
+func TestRealImageProcessor_Process_Pipeline_TooManySteps(t *testing.T) {
+	p := NewRealImageProcessor(t.TempDir(), puller.New(nil, puller.NewFileProvider()), 1, nil)
+
+	steps := []*pb.PipelineStep{
+		{Command: pb.CommandType_COMMAND_TYPE_FILTER, Parameters: &pb.PipelineStep_Filter{Filter: &pb.FilterParameters{FilterType: "grayscale"}}},
+		{Command: pb.CommandType_COMMAND_TYPE_FILTER, Parameters: &pb.PipelineStep_Filter{Filter: &pb.FilterParameters{FilterType: "invert"}}},
+	}
+	cmd := &pb.ImageCommand{
+		Id:         "pipeline-too-long",
+		ImageUrl:   writeTestPNG(t),
+		Command:    pb.CommandType_COMMAND_TYPE_PIPELINE,
+		Parameters: &pb.ImageCommand_Pipeline{Pipeline: &pb.PipelineParameters{Steps: steps}},
+	}
+
+	if _, err := p.Process(context.Background(), cmd); err == nil {
+		t.Fatal("expected error when the pipeline exceeds pipelineMaxSteps, got nil")
+	}
+}
+
+func TestRealImageProcessor_Process_Pipeline_StepFailureIdentifiesStep(t *testing.T) {
+	p := NewRealImageProcessor(t.TempDir(), puller.New(nil, puller.NewFileProvider()), 0, nil)
+
+	cmd := &pb.ImageCommand{
+		Id:       "pipeline-bad-filter",
+		ImageUrl: writeTestPNG(t),
+		Command:  pb.CommandType_COMMAND_TYPE_PIPELINE,
+		Parameters: &pb.ImageCommand_Pipeline{
+			Pipeline: &pb.PipelineParameters{
+				Steps: []*pb.PipelineStep{
+					{Command: pb.CommandType_COMMAND_TYPE_FILTER, Parameters: &pb.PipelineStep_Filter{Filter: &pb.FilterParameters{FilterType: "grayscale"}}},
+					{Command: pb.CommandType_COMMAND_TYPE_FILTER, Parameters: &pb.PipelineStep_Filter{Filter: &pb.FilterParameters{FilterType: "xyz"}}},
+				},
+			},
+		},
+	}
+
+	result, err := p.Process(context.Background(), cmd)
+	if err == nil {
+		t.Fatal("expected error for an unknown filter type, got nil")
+	}
+	const want = "step[1] filter:"
+	if len(result.ErrorMessage) < len(want) || result.ErrorMessage[:len(want)] != want {
+		t.Errorf("expected ErrorMessage to start with %q, got %q", want, result.ErrorMessage)
+	}
+}
+
+func TestRealImageProcessor_Process_RecordsThroughInjectedRecorder(t *testing.T) {
+	rec := &fakeRecorder{}
+	p := NewRealImageProcessor(t.TempDir(), puller.New(nil, puller.NewFileProvider()), 0, rec)
+
+	cmd := &pb.ImageCommand{
+		Id:       "recorder-1",
+		ImageUrl: writeTestPNG(t),
+		Command:  pb.CommandType_COMMAND_TYPE_RESIZE,
+		Parameters: &pb.ImageCommand_Resize{
+			Resize: &pb.ResizeParameters{Width: 10, Height: 10},
+		},
+	}
+
+	if _, err := p.Process(context.Background(), cmd); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	wantProcessed := cmd.Command.String() + ":success"
+	if len(rec.processed) != 1 || rec.processed[0] != wantProcessed {
+		t.Errorf("expected RecordMessageProcessed(%q), got %v", wantProcessed, rec.processed)
+	}
+	if len(rec.durations) != 1 || rec.durations[0] != cmd.Command.String() {
+		t.Errorf("expected ObserveMessageProcessingDuration(%q), got %v", cmd.Command.String(), rec.durations)
+	}
+}